@@ -1,46 +1,135 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/commands"
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/graph"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
 )
 
 const (
 	defaultManifestPath = "./auth-manifest.yaml"
 )
 
+// stringList permite repetir um flag (ex: -m a.yaml -m b.yaml)
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// split separa valores informados com vírgula (--role a,b equivale a --role a --role b)
+func (l stringList) split() []string {
+	var out []string
+	for _, value := range l {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
 func main() {
 	// Definir flags
+	var manifestPaths stringList
+	flag.Var(&manifestPaths, "manifest", "Caminho do manifest YAML, glob ou diretório (pode repetir; padrão: "+defaultManifestPath+")")
+	flag.Var(&manifestPaths, "m", "Caminho do manifest YAML, glob ou diretório (short)")
+
 	var (
-		manifestPath = flag.String("manifest", defaultManifestPath, "Caminho do arquivo manifest YAML")
-		manifestPathShort = flag.String("m", defaultManifestPath, "Caminho do arquivo manifest YAML (short)")
+		profileName = flag.String("profile", "", "Perfil do ~/.config/sagep-auth/config.yaml (override de SAGEP_AUTH_PROFILE e current_profile)")
 		authURL = flag.String("url", "", "URL base do serviço sagep-auth (override)")
 		authToken = flag.String("token", "", "Token JWT de autenticação (override, uso normal)")
 		authSecret = flag.String("secret", "", "Secret compartilhado para HMAC (override, bootstrap)")
+		caCert = flag.String("ca-cert", "", "CA adicional em PEM para verificar o servidor (ex: CA interna)")
+		clientCert = flag.String("client-cert", "", "Certificado PEM do cliente para mTLS (com --client-key)")
+		clientKey = flag.String("client-key", "", "Chave privada PEM do cliente para mTLS")
+		insecureSkipVerify = flag.Bool("insecure-skip-verify", false, "NÃO verifica o certificado TLS do servidor (inseguro; apenas diagnóstico)")
+		timeout = flag.Duration("timeout", 0, "Tempo máximo por requisição (padrão: 30s)")
+		connectTimeout = flag.Duration("connect-timeout", 0, "Tempo máximo de conexão + handshake TLS (padrão: 10s)")
+		proxy = flag.String("proxy", "", "URL do proxy (ex: http://proxy:3128) ou 'none' (padrão: HTTPS_PROXY/NO_PROXY)")
+		glossaryPath = flag.String("glossary", "", "Glossário de entidades pt-BR → código (padrão: glossary.yaml ao lado do manifest)")
+		tenantsPath = flag.String("tenants", "", "Registro de unidades e secretarias conhecidas (padrão: tenants.yaml ao lado do manifest)")
 		help = flag.Bool("help", false, "Exibir ajuda")
 	)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Uso: %s [opções] <comando>\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Comandos:\n")
-		fmt.Fprintf(os.Stderr, "  init    Cria um novo manifest interativamente\n")
-		fmt.Fprintf(os.Stderr, "  sync    Sincroniza o manifest com o serviço sagep-auth\n\n")
+		fmt.Fprintf(os.Stderr, "  init      Cria um novo manifest interativamente\n")
+		fmt.Fprintf(os.Stderr, "  sync      Sincroniza o manifest com o serviço sagep-auth\n")
+		fmt.Fprintf(os.Stderr, "  validate  Valida manifests sem contatar o servidor (estrutura, referências, convenções)\n")
+		fmt.Fprintf(os.Stderr, "  status    Mostra quais ambientes estão atrás do manifest (auth-manifest.lock, sem contatar o servidor)\n")
+		fmt.Fprintf(os.Stderr, "  drift     Compara o servidor com os manifests em cada perfil (saída 0 = em sincronia, 2 = drift, 1 = erro)\n")
+		fmt.Fprintf(os.Stderr, "  users import  Acrescenta/atualiza usuários a partir de um CSV (export de planilha)\n")
+		fmt.Fprintf(os.Stderr, "  show      Exibe o manifest efetivo (ou --payload do sync) com senhas e secrets mascarados\n")
+		fmt.Fprintf(os.Stderr, "  matrix    Gera a matriz roles × permissions (CSV, HTML ou Markdown; --users inclui users × roles)\n")
+		fmt.Fprintf(os.Stderr, "  graph     Exporta users → roles → permissions → subjects em DOT ou Mermaid (--role, --subject, --user)\n")
+		fmt.Fprintf(os.Stderr, "  keygen    Gera uma chave X25519 (ou --aes) para criptografar senhas do manifest\n")
+		fmt.Fprintf(os.Stderr, "  encrypt   Criptografa password e campos !sensitive como ENC[...] (--recipient pode repetir)\n")
+		fmt.Fprintf(os.Stderr, "  decrypt   Decifra os valores ENC[...] (stdout, ou --in-place)\n")
+		fmt.Fprintf(os.Stderr, "  migrate   Atualiza manifests antigos para a apiVersion atual (%s), listando cada decisão\n", manifest.CurrentAPIVersion)
+		fmt.Fprintf(os.Stderr, "  lint      Verifica se as permissions seguem o bloco conventions (--fix corrige)\n")
+		fmt.Fprintf(os.Stderr, "  login     Obtém um token JWT em /v1/authenticate e salva nas credenciais do perfil\n")
+		fmt.Fprintf(os.Stderr, "  profile   Gerencia perfis de conexão (list, use <nome>, show [nome])\n")
+		fmt.Fprintf(os.Stderr, "  apply-bundle  Aplica um bundle gerado por 'sync --bundle' (ambientes sem acesso do CI)\n")
+		fmt.Fprintf(os.Stderr, "  mock-server  Sobe um sagep-auth em memória para desenvolvimento local\n\n")
 		fmt.Fprintf(os.Stderr, "Opções:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nVariáveis de ambiente:\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_PROFILE Perfil do config.yaml (opcional; perfil > .env > variáveis de ambiente; token/secret vêm só do perfil, de secret_env/token_env ou dos flags)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_CONFIG  Arquivo de perfis (opcional, padrão ~/.config/sagep-auth/config.yaml)\n")
 		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_URL     URL base do serviço sagep-auth (obrigatório)\n")
-		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_SECRET  Secret compartilhado para HMAC (obrigatório)\n")
-		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_TOKEN   Token JWT (opcional, para uso normal)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_SECRET  Secret compartilhado para HMAC (obrigatório; ignorado com perfil ativo)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_TOKEN   Token JWT (opcional, para uso normal; sem ele, usa o token salvo pelo login)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_SIGNATURE_VERSION  Esquema HMAC: v1 (padrão) ou v2 (requisição canônica + nonce)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_KEY_ID  Identificador do secret para rotação (opcional, header X-Key-Id)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_CA_CERT, SAGEP_AUTH_CLIENT_CERT, SAGEP_AUTH_CLIENT_KEY  TLS (opcional, equivalentes aos flags)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_INSECURE_SKIP_VERIFY  true para não verificar o certificado do servidor (inseguro)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_TIMEOUT, SAGEP_AUTH_CONNECT_TIMEOUT  Timeouts (opcional, padrão 30s / 10s)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_PROXY   URL do proxy ou 'none' (opcional)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_KEY_FILE, SAGEP_AUTH_KEY  Chaves para decifrar ENC[...] (padrão: ~/.config/sagep-auth/keys.txt)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_MAX_RETRIES  Retries em falhas transitórias (opcional, padrão 3)\n")
+		fmt.Fprintf(os.Stderr, "  SAGEP_AUTH_RETRY_DELAY  Intervalo inicial do backoff (opcional, padrão 500ms)\n")
 		fmt.Fprintf(os.Stderr, "\n  SAGEP_AUTH_SECRET é obrigatório e deve ser o mesmo valor do BOOTSTRAP_SECRET no servidor\n\n")
 		fmt.Fprintf(os.Stderr, "Exemplos:\n")
 		fmt.Fprintf(os.Stderr, "  %s init  # Cria manifest interativamente\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s init --generate-passwords --passwords-file senhas.csv\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --manifest ./auth-manifest.yaml sync\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -m ./auth-manifest.yaml sync\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s sync  # usa ./auth-manifest.yaml (padrão)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m apps/biopass.yaml -m apps/crv.yaml sync\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m './apps/*/auth-manifest.yaml' sync --concurrency 8\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s sync --report junit.xml --report summary.md\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s sync --force  # ignora o auth-manifest.lock\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s status\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m ./manifests drift --output json  # todos os perfis do config.yaml\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m envs/producao.yaml encrypt --recipient sagep-x25519:... --recipient sagep-x25519:...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s users import --map email=E-mail,roles=Perfis operadores.csv\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s sync --users=authoritative --removed-users=unlink\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s matrix --users -o matriz.html\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s graph --subject 'biopass.devices' -o grafo.md  # bloco mermaid para o merge request\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -m './apps/*/auth-manifest.yaml' migrate --dry-run\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s lint --fix\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s login --email admin@sagep.com.br\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --profile homologacao sync\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s profile use producao\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s sync --bundle sync-bundle.json --bundle-key \"$BUNDLE_KEY\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s apply-bundle --require-signature sync-bundle.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s mock-server --addr :8080 --secret dev --seed ./auth-manifest.yaml\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -62,7 +151,9 @@ func main() {
 	// Detectar se flags foram passados após o comando (ordem incorreta)
 	if len(args) > 1 {
 		nextArg := args[1]
-		if nextArg == "--manifest" || nextArg == "-m" || nextArg == "--url" || nextArg == "--token" || nextArg == "--secret" {
+		if nextArg == "--manifest" || nextArg == "-m" || nextArg == "--url" || nextArg == "--token" || nextArg == "--secret" || nextArg == "--glossary" || nextArg == "--tenants" || nextArg == "--profile" ||
+			nextArg == "--ca-cert" || nextArg == "--client-cert" || nextArg == "--client-key" || nextArg == "--insecure-skip-verify" ||
+			nextArg == "--timeout" || nextArg == "--connect-timeout" || nextArg == "--proxy" {
 			fmt.Fprintf(os.Stderr, "❌ Erro: Os flags devem vir ANTES do comando!\n\n")
 			fmt.Fprintf(os.Stderr, "❌ Forma incorreta: %s %s %s ...\n", os.Args[0], args[0], nextArg)
 			fmt.Fprintf(os.Stderr, "✅ Forma correta:   %s %s %s ...\n\n", os.Args[0], nextArg, args[0])
@@ -75,50 +166,392 @@ func main() {
 
 	command := args[0]
 
-	// Determinar qual manifest usar: -m tem precedência sobre --manifest
-	// Se nenhum for fornecido, usa o default
-	manifest := defaultManifestPath
-	
-	// Verificar se --manifest foi usado (diferente do default)
-	if *manifestPath != defaultManifestPath {
-		manifest = *manifestPath
+	// Determinar quais manifests usar (padrão: ./auth-manifest.yaml)
+	if len(manifestPaths) == 0 {
+		manifestPaths = stringList{defaultManifestPath}
 	}
-	
-	// Verificar se -m foi usado (tem precedência sobre --manifest)
-	if *manifestPathShort != defaultManifestPath {
-		manifest = *manifestPathShort
+
+	// Comandos que operam sobre um único manifest
+	singleManifest := func() string {
+		if len(manifestPaths) > 1 {
+			fmt.Fprintf(os.Stderr, "Erro: o comando '%s' aceita apenas um manifest\n", command)
+			os.Exit(1)
+		}
+		return manifestPaths[0]
+	}
+
+	// Flags de conexão sobrescrevem perfil, .env e variáveis de ambiente
+	applyConnectionFlags := func(cfg *config.Config) {
+		if *caCert != "" {
+			cfg.CACert = *caCert
+		}
+		if *clientCert != "" {
+			cfg.ClientCert = *clientCert
+		}
+		if *clientKey != "" {
+			cfg.ClientKey = *clientKey
+		}
+		if *insecureSkipVerify {
+			cfg.InsecureSkipVerify = true
+		}
+		if *timeout > 0 {
+			cfg.Timeout = *timeout
+		}
+		if *connectTimeout > 0 {
+			cfg.ConnectTimeout = *connectTimeout
+		}
+		if *proxy != "" {
+			cfg.Proxy = *proxy
+		}
 	}
 
 	switch command {
 	case "init":
-		// Determinar caminho do manifest para criar
-		initManifestPath := defaultManifestPath
-		if *manifestPath != defaultManifestPath {
-			initManifestPath = *manifestPath
-		}
-		if *manifestPathShort != defaultManifestPath {
-			initManifestPath = *manifestPathShort
+		initFlags := flag.NewFlagSet("init", flag.ExitOnError)
+		generatePasswords := initFlags.Bool("generate-passwords", false, "Gera senhas fortes para os novos usuários (exibidas uma única vez ou gravadas em --passwords-file)")
+		passwordsFile := initFlags.String("passwords-file", "", "Grava as senhas geradas neste arquivo (0600, não sobrescreve) em vez do stdout")
+		var recipients stringList
+		initFlags.Var(&recipients, "recipient", "Criptografa as senhas do manifest para este destinatário (pode repetir; padrão com --generate-passwords: chaves locais)")
+		recipientsFile := initFlags.String("recipients-file", "", "Arquivo com um destinatário por linha")
+		initFlags.Parse(args[1:])
+
+		if *passwordsFile != "" && !*generatePasswords {
+			fmt.Fprintf(os.Stderr, "Erro: --passwords-file requer --generate-passwords\n")
+			os.Exit(1)
 		}
 
-		if err := commands.RunInit(initManifestPath); err != nil {
+		initOpts := commands.InitOptions{
+			GeneratePasswords: *generatePasswords,
+			PasswordsFile:     *passwordsFile,
+			Encrypt:           commands.EncryptOptions{Recipients: recipients, RecipientsFile: *recipientsFile},
+		}
+		if err := commands.RunInit(singleManifest(), *glossaryPath, initOpts); err != nil {
 			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
 			os.Exit(1)
 		}
 
 	case "sync":
+		syncFlags := flag.NewFlagSet("sync", flag.ExitOnError)
+		concurrency := syncFlags.Int("concurrency", commands.DefaultSyncConcurrency, "Número máximo de manifests sincronizados em paralelo")
+		retries := syncFlags.Int("retries", -1, "Retries em falhas transitórias: rede, 5xx, 429 (padrão: SAGEP_AUTH_MAX_RETRIES ou 3)")
+		retryDelay := syncFlags.Duration("retry-delay", 0, "Intervalo inicial do backoff exponencial (padrão: SAGEP_AUTH_RETRY_DELAY ou 500ms)")
+		only := syncFlags.String("only", "", "Sincroniza apenas as seções informadas (ex: permissions,roles)")
+		skip := syncFlags.String("skip", "", "Não sincroniza as seções informadas (ex: users)")
+		output := syncFlags.String("output", "table", "Formato da saída: table, json ou yaml (json/yaml: progresso vai para stderr)")
+		strict := syncFlags.Bool("strict", false, "Falha se algum item for ignorado pelo servidor ou estiver ausente na resposta")
+		yes := syncFlags.Bool("yes", false, "Confirma o sync em perfil protegido (protected: true) sem perguntar")
+		force := syncFlags.Bool("force", false, "Sincroniza mesmo se o auth-manifest.lock indicar que o ambiente está em dia")
+		usersMode := syncFlags.String("users", commands.UsersMerge, "merge (padrão) ou authoritative: usuários vinculados fora do manifest são desativados/desvinculados")
+		removedUsers := syncFlags.String("removed-users", "deactivate", "Com --users=authoritative: deactivate (desativa) ou unlink (remove vínculo e roles da aplicação)")
+		bundlePath := syncFlags.String("bundle", "", "Grava o payload em um bundle offline (não contata o servidor; ver apply-bundle)")
+		bundleKey := syncFlags.String("bundle-key", "", "Chave para assinar o bundle (padrão: SAGEP_AUTH_BUNDLE_KEY; opcional)")
+		bundleKeyID := syncFlags.String("bundle-key-id", "", "Identificador da chave do bundle (opcional)")
+		var reports stringList
+		syncFlags.Var(&reports, "report", "Gera relatório: .xml (JUnit) ou .md (Markdown); pode repetir")
+		syncFlags.Parse(args[1:])
+
+		sections, err := manifest.ResolveSections(*only, *skip)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			os.Exit(1)
+		}
+
+		syncOpts := commands.SyncOptions{Concurrency: *concurrency, Sections: sections, Output: *output, Reports: reports, Strict: *strict, Yes: *yes, Force: *force, TenantsPath: *tenantsPath, Users: *usersMode, RemovedUsers: *removedUsers}
+
+		// Bundle offline: não precisa de URL nem secret
+		if *bundlePath != "" {
+			syncOpts.Bundle = *bundlePath
+			syncOpts.BundleKey = *bundleKey
+			if syncOpts.BundleKey == "" {
+				syncOpts.BundleKey = os.Getenv("SAGEP_AUTH_BUNDLE_KEY")
+			}
+			syncOpts.BundleKeyID = *bundleKeyID
+			commands.RunSyncBundleWithExit(manifestPaths, syncOpts)
+			return
+		}
+
 		// Carregar configuração
-		cfg, err := config.LoadConfig(*authURL, *authToken, *authSecret)
+		cfg, err := config.LoadConfig(*profileName, *authURL, *authToken, *authSecret)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Erro de configuração: %v\n", err)
 			os.Exit(1)
 		}
 
+		applyConnectionFlags(cfg)
+		if *retries >= 0 {
+			cfg.MaxRetries = *retries
+		}
+		if *retryDelay > 0 {
+			cfg.RetryDelay = *retryDelay
+		}
+
 		// Executar sync
-		commands.RunSyncWithExit(manifest, cfg)
+		commands.RunSyncWithExit(manifestPaths, cfg, syncOpts)
+
+	case "validate":
+		validateFlags := flag.NewFlagSet("validate", flag.ExitOnError)
+		var reports stringList
+		validateFlags.Var(&reports, "report", "Gera relatório: .xml (JUnit) ou .md (Markdown); pode repetir")
+		validateFlags.Parse(args[1:])
+
+		commands.RunValidateWithExit(manifestPaths, commands.ValidateOptions{GlossaryPath: *glossaryPath, TenantsPath: *tenantsPath, Reports: reports})
+
+	case "status":
+		statusFlags := flag.NewFlagSet("status", flag.ExitOnError)
+		statusFlags.Parse(args[1:])
+
+		commands.RunStatusWithExit(manifestPaths)
+
+	case "drift":
+		// ContinueOnError: o código 2 do flag colidiria com DriftExitDrift
+		driftFlags := flag.NewFlagSet("drift", flag.ContinueOnError)
+		only := driftFlags.String("only", "", "Compara apenas as seções informadas (ex: roles,users)")
+		skip := driftFlags.String("skip", "", "Não compara as seções informadas (ex: users)")
+		output := driftFlags.String("output", "table", "Formato da saída: table, json ou yaml (json/yaml: progresso vai para stderr)")
+		if err := driftFlags.Parse(args[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(commands.DriftExitInSync)
+			}
+			os.Exit(commands.DriftExitError)
+		}
+
+		sections, err := manifest.ResolveSections(*only, *skip)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			os.Exit(commands.DriftExitError)
+		}
+
+		// --profile verifica um perfil; --url, um servidor avulso; sem eles, todos os perfis do config.yaml
+		var profiles []string
+		if *profileName != "" {
+			profiles = []string{*profileName}
+		} else if *authURL != "" {
+			profiles = []string{""}
+		}
+
+		commands.RunDriftWithExit(manifestPaths, commands.DriftOptions{
+			Profiles: profiles,
+			Sections: sections,
+			Output:   *output,
+			LoadConfig: func(profile string) (*config.Config, error) {
+				cfg, err := config.LoadConfig(profile, *authURL, *authToken, *authSecret)
+				if err != nil {
+					return nil, err
+				}
+				applyConnectionFlags(cfg)
+				return cfg, nil
+			},
+		})
+
+	case "show":
+		showFlags := flag.NewFlagSet("show", flag.ExitOnError)
+		payload := showFlags.Bool("payload", false, "Exibe o payload final do sync em vez do manifest")
+		only := showFlags.String("only", "", "Exibe apenas as seções informadas (ex: roles,users)")
+		skip := showFlags.String("skip", "", "Não exibe as seções informadas (ex: users)")
+		hash := showFlags.Bool("hash", false, "Troca senhas e secrets por um hash curto (sha256) em vez de ***")
+		output := showFlags.String("output", "", "Formato: yaml ou json (padrão: yaml; json com --payload)")
+		showFlags.Parse(args[1:])
+
+		sections, err := manifest.ResolveSections(*only, *skip)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			os.Exit(1)
+		}
+
+		commands.RunShowWithExit(singleManifest(), commands.ShowOptions{Payload: *payload, Sections: sections, Hash: *hash, Output: *output})
+
+	case "matrix":
+		matrixFlags := flag.NewFlagSet("matrix", flag.ExitOnError)
+		format := matrixFlags.String("format", "", "Formato: csv, html ou markdown (padrão: pela extensão de -o; sem -o, markdown)")
+		output := matrixFlags.String("o", "", "Arquivo de saída (padrão: stdout)")
+		users := matrixFlags.Bool("users", false, "Inclui a visão users × roles (HTML e Markdown)")
+		table := matrixFlags.String("table", "", "CSV: tabela do arquivo, permissions (padrão) ou users")
+		matrixFlags.Parse(args[1:])
+
+		commands.RunMatrixWithExit(singleManifest(), commands.MatrixOptions{Format: *format, Output: *output, Users: *users, Table: *table})
+
+	case "graph":
+		graphFlags := flag.NewFlagSet("graph", flag.ExitOnError)
+		format := graphFlags.String("format", "", "Formato: dot ou mermaid (padrão: pela extensão de -o; sem -o, dot)")
+		output := graphFlags.String("o", "", "Arquivo de saída (padrão: stdout); .md gera um bloco ```mermaid")
+		var roles, subjects, users stringList
+		graphFlags.Var(&roles, "role", "Apenas caminhos que passam por estas roles (pode repetir ou separar por vírgula)")
+		graphFlags.Var(&subjects, "subject", "Apenas caminhos até estes subjects; aceita wildcard, ex: biopass.* (pode repetir)")
+		graphFlags.Var(&users, "user", "Apenas caminhos destes usuários, por email (pode repetir)")
+		graphFlags.Parse(args[1:])
+
+		commands.RunGraphWithExit(manifestPaths, commands.GraphOptions{
+			Format: *format,
+			Output: *output,
+			Filter: graph.Filter{Roles: roles.split(), Subjects: subjects.split(), Users: users.split()},
+		})
+
+	case "migrate":
+		migrateFlags := flag.NewFlagSet("migrate", flag.ExitOnError)
+		to := migrateFlags.String("to", "", "apiVersion de destino (padrão: "+manifest.CurrentAPIVersion+")")
+		dryRun := migrateFlags.Bool("dry-run", false, "Exibe as decisões sem alterar os arquivos")
+		renameRoles := migrateFlags.Bool("rename-roles", false, "Converte codes de role do formato antigo (BIOPASS_ADMIN → biopass.admin) e atualiza os usuários")
+		force := migrateFlags.Bool("force", false, "Grava mesmo com itens pendentes de revisão manual (⚠️); o migrate não os listará de novo")
+		migrateFlags.Parse(args[1:])
+
+		commands.RunMigrateWithExit(manifestPaths, commands.MigrateOptions{To: *to, DryRun: *dryRun, RenameRoles: *renameRoles, Force: *force})
+
+	case "users":
+		if len(args) < 2 || args[1] != "import" {
+			fmt.Fprintf(os.Stderr, "Erro: subcomando não especificado (use: users import <arquivo.csv>)\n")
+			os.Exit(1)
+		}
+		importFlags := flag.NewFlagSet("users import", flag.ExitOnError)
+		var mappings stringList
+		importFlags.Var(&mappings, "map", "Coluna de cada campo: email=E-mail,name=Nome,tenant_id=Unidade,roles=Perfis,password=Senha,password_ref=Ref (env:VAR ou file:caminho) (pode repetir)")
+		delimiter := importFlags.String("delimiter", "", "Separador do CSV: ',', ';' ou tab (padrão: detecta pelo cabeçalho)")
+		dryRun := importFlags.Bool("dry-run", false, "Exibe o resumo sem alterar o manifest")
+		generatePasswords := importFlags.Bool("generate-passwords", false, "Gera senhas fortes para usuários novos sem senha na planilha")
+		passwordsFile := importFlags.String("passwords-file", "", "Grava as senhas geradas neste arquivo (0600) em vez de exibi-las")
+		encrypt := importFlags.Bool("encrypt", false, "Grava as senhas da planilha criptografadas (ENC[...]); as geradas e as de password_ref são sempre criptografadas")
+		var recipients stringList
+		importFlags.Var(&recipients, "recipient", "Destinatário das senhas criptografadas (pode repetir; padrão: chaves locais)")
+		recipientsFile := importFlags.String("recipients-file", "", "Arquivo com um destinatário por linha")
+		importFlags.Parse(args[2:])
+		// Flags também são aceitos depois do arquivo (users import users.csv --dry-run)
+		if importFlags.NArg() == 0 {
+			fmt.Fprintf(os.Stderr, "Erro: uso: users import [opções] <arquivo.csv>\n")
+			os.Exit(1)
+		}
+		csvPath := importFlags.Arg(0)
+		importFlags.Parse(importFlags.Args()[1:])
+		if importFlags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Erro: uso: users import [opções] <arquivo.csv>\n")
+			os.Exit(1)
+		}
+
+		mapping := make(map[string]string)
+		for _, m := range mappings {
+			for _, pair := range strings.Split(m, ",") {
+				field, column, ok := strings.Cut(pair, "=")
+				if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(column) == "" {
+					fmt.Fprintf(os.Stderr, "Erro: --map espera campo=coluna (atual: %q)\n", pair)
+					os.Exit(1)
+				}
+				mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+			}
+		}
+
+		commands.RunUsersImportWithExit(singleManifest(), csvPath, commands.UsersImportOptions{
+			Mapping:           mapping,
+			Delimiter:         *delimiter,
+			DryRun:            *dryRun,
+			GeneratePasswords: *generatePasswords,
+			PasswordsFile:     *passwordsFile,
+			TenantsPath:       *tenantsPath,
+			Encrypt:           *encrypt,
+			EncryptOptions:    commands.EncryptOptions{Recipients: recipients, RecipientsFile: *recipientsFile},
+		})
+
+	case "keygen":
+		keygenFlags := flag.NewFlagSet("keygen", flag.ExitOnError)
+		output := keygenFlags.String("o", "", "Arquivo de chaves (acrescenta; '-' = stdout; padrão: ~/.config/sagep-auth/keys.txt)")
+		aesKey := keygenFlags.Bool("aes", false, "Gera uma chave simétrica AES-256 em vez de X25519")
+		keygenFlags.Parse(args[1:])
+
+		commands.RunKeygenWithExit(commands.KeygenOptions{Output: *output, AES: *aesKey})
+
+	case "encrypt":
+		encryptFlags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+		var recipients, keyFiles stringList
+		encryptFlags.Var(&recipients, "recipient", "Destinatário: sagep-x25519:... ou SAGEP-AES256-KEY:... (pode repetir)")
+		recipientsFile := encryptFlags.String("recipients-file", "", "Arquivo com um destinatário por linha")
+		encryptFlags.Var(&keyFiles, "key-file", "Sem destinatários, criptografa para as chaves deste arquivo (pode repetir)")
+		encryptFlags.Parse(args[1:])
+
+		commands.RunEncryptWithExit(manifestPaths, commands.EncryptOptions{Recipients: recipients, RecipientsFile: *recipientsFile, KeyFiles: keyFiles})
+
+	case "decrypt":
+		decryptFlags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+		var keyFiles stringList
+		decryptFlags.Var(&keyFiles, "key-file", "Arquivo de chaves (pode repetir; padrão: SAGEP_AUTH_KEY_FILE / SAGEP_AUTH_KEY / keys.txt)")
+		inPlace := decryptFlags.Bool("in-place", false, "Regrava o manifest em texto claro (padrão: imprime no stdout)")
+		decryptFlags.Parse(args[1:])
+
+		commands.RunDecryptWithExit(singleManifest(), commands.DecryptOptions{KeyFiles: keyFiles, InPlace: *inPlace})
+
+	case "lint":
+		lintFlags := flag.NewFlagSet("lint", flag.ExitOnError)
+		fix := lintFlags.Bool("fix", false, "Reescreve code/subject das permissions que não seguem as convenções")
+		lintFlags.Parse(args[1:])
+
+		commands.RunLintWithExit(singleManifest(), *glossaryPath, *fix)
+
+	case "login":
+		loginFlags := flag.NewFlagSet("login", flag.ExitOnError)
+		email := loginFlags.String("email", "", "Email do usuário (perguntado se omitido)")
+		password := loginFlags.String("password", "", "Senha (evite: fica no histórico do shell; prefira --password-stdin)")
+		passwordStdin := loginFlags.Bool("password-stdin", false, "Lê a senha da primeira linha do stdin")
+		appCode := loginFlags.String("app", "", "application_code enviado ao /v1/authenticate (opcional)")
+		loginFlags.Parse(args[1:])
+
+		// login não exige SAGEP_AUTH_SECRET
+		cfg, err := config.LoadBaseConfig(*profileName, *authURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro de configuração: %v\n", err)
+			os.Exit(1)
+		}
+
+		applyConnectionFlags(cfg)
+
+		commands.RunLoginWithExit(cfg, commands.LoginOptions{Email: *email, Password: *password, PasswordStdin: *passwordStdin, AppCode: *appCode})
+
+	case "profile":
+		commands.RunProfileWithExit(args[1:], *profileName)
+
+	case "apply-bundle":
+		applyFlags := flag.NewFlagSet("apply-bundle", flag.ExitOnError)
+		bundleKey := applyFlags.String("bundle-key", "", "Chave para verificar a assinatura do bundle (padrão: SAGEP_AUTH_BUNDLE_KEY)")
+		requireSignature := applyFlags.Bool("require-signature", false, "Rejeita bundles sem assinatura")
+		strict := applyFlags.Bool("strict", false, "Falha se algum item for ignorado pelo servidor ou estiver ausente na resposta")
+		yes := applyFlags.Bool("yes", false, "Confirma em perfil protegido (protected: true) sem perguntar")
+		applyFlags.Parse(args[1:])
+
+		if applyFlags.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "Uso: %s [opções] apply-bundle [--bundle-key K] <bundle.json>\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfig(*profileName, *authURL, *authToken, *authSecret)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro de configuração: %v\n", err)
+			os.Exit(1)
+		}
+		applyConnectionFlags(cfg)
+
+		key := *bundleKey
+		if key == "" {
+			key = os.Getenv("SAGEP_AUTH_BUNDLE_KEY")
+		}
+		commands.RunApplyBundleWithExit(applyFlags.Arg(0), cfg, commands.ApplyBundleOptions{BundleKey: key, RequireSignature: *requireSignature, Strict: *strict, Yes: *yes})
+
+	case "mock-server":
+		mockFlags := flag.NewFlagSet("mock-server", flag.ExitOnError)
+		addr := mockFlags.String("addr", ":8080", "Endereço de escuta")
+		secret := mockFlags.String("secret", "", "BOOTSTRAP_SECRET aceito (padrão: --secret global, SAGEP_AUTH_SECRET ou BOOTSTRAP_SECRET)")
+		tokenTTL := mockFlags.Duration("token-ttl", time.Hour, "Validade dos tokens emitidos por /v1/authenticate")
+		var keys, seeds stringList
+		mockFlags.Var(&keys, "key", "Secret por X-Key-Id no formato id=secret (pode repetir)")
+		mockFlags.Var(&seeds, "seed", "Manifest, glob ou diretório carregado na inicialização (pode repetir)")
+		mockFlags.Parse(args[1:])
+
+		mockSecret := *secret
+		for _, candidate := range []string{*authSecret, os.Getenv("SAGEP_AUTH_SECRET"), os.Getenv("BOOTSTRAP_SECRET")} {
+			if mockSecret == "" {
+				mockSecret = candidate
+			}
+		}
+
+		commands.RunMockServerWithExit(commands.MockServerOptions{Addr: *addr, Secret: mockSecret, Keys: keys, Seeds: seeds, TokenTTL: *tokenTTL})
 
 	default:
 		fmt.Fprintf(os.Stderr, "Erro: comando desconhecido '%s'\n\n", command)
-		fmt.Fprintf(os.Stderr, "Comandos disponíveis: init, sync\n")
+		fmt.Fprintf(os.Stderr, "Comandos disponíveis: init, sync, validate, status, drift, users, show, matrix, graph, keygen, encrypt, decrypt, migrate, lint, login, profile, apply-bundle, mock-server\n")
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,193 @@
+// Package bundle implementa os bundles de sync offline (sync --bundle / apply-bundle)
+//
+// Um bundle guarda o payload JSON exato que o sync enviaria ao servidor, com metadados
+// (hash do manifest, aplicação, data de criação) e, opcionalmente, uma assinatura
+// HMAC-SHA256 com uma chave de bundle compartilhada entre o CI e o ambiente isolado.
+// A autenticação no servidor (HMAC ou JWT) é feita apenas no apply-bundle, com o
+// secret do ambiente de destino
+package bundle
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Identificação do formato
+const (
+	Kind          = "sagep-auth-sync-bundle"
+	SchemaVersion = 1
+)
+
+// SignatureAlgorithm é o único algoritmo de assinatura suportado
+const SignatureAlgorithm = "hmac-sha256"
+
+// Entry é o payload de sync de uma aplicação
+type Entry struct {
+	Application   string          `json:"application"`
+	ManifestPath  string          `json:"manifest_path"`
+	ManifestHash  string          `json:"manifest_hash"`
+	Sections      []string        `json:"sections,omitempty"` // nil = todas as seções
+	PayloadSHA256 string          `json:"payload_sha256"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Signature é a assinatura opcional do bundle
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id,omitempty"`
+	Value     string `json:"value"`
+}
+
+// Bundle é o arquivo gerado por sync --bundle
+type Bundle struct {
+	Kind          string     `json:"kind"`
+	SchemaVersion int        `json:"schema_version"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Entries       []Entry    `json:"applications"`
+	Signature     *Signature `json:"signature,omitempty"`
+}
+
+// New cria um bundle vazio
+func New() *Bundle {
+	return &Bundle{Kind: Kind, SchemaVersion: SchemaVersion, CreatedAt: time.Now().UTC()}
+}
+
+// Add adiciona o payload de uma aplicação ao bundle
+func (b *Bundle) Add(application, manifestPath, manifestHash string, sections []string, payload []byte) {
+	b.Entries = append(b.Entries, Entry{
+		Application:   application,
+		ManifestPath:  manifestPath,
+		ManifestHash:  manifestHash,
+		Sections:      sections,
+		PayloadSHA256: payloadHash(payload),
+		Payload:       json.RawMessage(payload),
+	})
+}
+
+// PayloadBytes retorna o payload exato a enviar (compacto, como gerado pelo sync)
+// O arquivo é indentado para leitura; compactar devolve os bytes originais
+func (e *Entry) PayloadBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, e.Payload); err != nil {
+		return nil, fmt.Errorf("payload de %s inválido: %w", e.Application, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign assina o bundle com a chave de bundle (HMAC-SHA256)
+func (b *Bundle) Sign(key, keyID string) {
+	b.Signature = &Signature{
+		Algorithm: SignatureAlgorithm,
+		KeyID:     keyID,
+		Value:     b.signature(key),
+	}
+}
+
+// Verify confere os hashes dos payloads e, se houver chave, a assinatura
+// Bundle assinado sem chave informada é erro: a assinatura existe para ser verificada
+// Com chave informada (ou requireSignature), bundles sem assinatura são rejeitados:
+// remover a assinatura não pode ser uma forma de passar pela verificação
+func (b *Bundle) Verify(key string, requireSignature bool) error {
+	if b.Kind != Kind {
+		return fmt.Errorf("arquivo não é um bundle de sync (kind %q)", b.Kind)
+	}
+	if b.SchemaVersion != SchemaVersion {
+		return fmt.Errorf("schema_version %d não suportado (esperado %d)", b.SchemaVersion, SchemaVersion)
+	}
+	if len(b.Entries) == 0 {
+		return fmt.Errorf("bundle sem aplicações")
+	}
+
+	for i := range b.Entries {
+		e := &b.Entries[i]
+		payload, err := e.PayloadBytes()
+		if err != nil {
+			return err
+		}
+		if payloadHash(payload) != e.PayloadSHA256 {
+			return fmt.Errorf("payload de %s foi alterado (payload_sha256 não confere)", e.Application)
+		}
+		var head struct {
+			Application struct {
+				Code string `json:"code"`
+			} `json:"application"`
+		}
+		if err := json.Unmarshal(payload, &head); err != nil || head.Application.Code != e.Application {
+			return fmt.Errorf("payload não corresponde à aplicação %s", e.Application)
+		}
+	}
+
+	if b.Signature == nil {
+		switch {
+		case key != "":
+			return fmt.Errorf("bundle sem assinatura, mas uma chave de bundle foi informada (--bundle-key ou SAGEP_AUTH_BUNDLE_KEY)")
+		case requireSignature:
+			return fmt.Errorf("bundle sem assinatura (--require-signature)")
+		}
+		return nil
+	}
+	if b.Signature.Algorithm != SignatureAlgorithm {
+		return fmt.Errorf("algoritmo de assinatura %q não suportado", b.Signature.Algorithm)
+	}
+	if key == "" {
+		keyInfo := ""
+		if b.Signature.KeyID != "" {
+			keyInfo = fmt.Sprintf(" com a chave %q", b.Signature.KeyID)
+		}
+		return fmt.Errorf("bundle assinado%s: informe a chave com --bundle-key ou SAGEP_AUTH_BUNDLE_KEY", keyInfo)
+	}
+	if !hmac.Equal([]byte(b.signature(key)), []byte(strings.ToLower(b.Signature.Value))) {
+		return fmt.Errorf("assinatura do bundle inválida (chave incorreta ou bundle alterado)")
+	}
+	return nil
+}
+
+// signature calcula o HMAC da forma canônica do bundle
+// Uma linha por campo: kind, schema_version, created_at e, por aplicação,
+// application, manifest_hash e payload_sha256 (os payloads entram pelo hash)
+func (b *Bundle) signature(key string) string {
+	lines := []string{b.Kind, fmt.Sprintf("%d", b.SchemaVersion), b.CreatedAt.UTC().Format(time.RFC3339Nano)}
+	for _, e := range b.Entries {
+		lines = append(lines, e.Application, e.ManifestHash, e.PayloadSHA256)
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Write grava o bundle (JSON indentado) no arquivo
+func (b *Bundle) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar bundle: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar bundle %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read carrega um bundle do arquivo (sem verificar; use Verify)
+func Read(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler bundle: %w", err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do bundle %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+func payloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+package bundle
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPayload = `{"application":{"code":"sagep-biopass","name":"Biopass"},"permissions":[]}`
+
+func newTestBundle(key string) *Bundle {
+	b := New()
+	b.Add("sagep-biopass", "auth-manifest.yaml", "hash", nil, []byte(testPayload))
+	if key != "" {
+		b.Sign(key, "ci")
+	}
+	return b
+}
+
+// roundTrip grava e relê o bundle, como acontece entre o sync --bundle e o apply-bundle
+func roundTrip(t *testing.T, b *Bundle) *Bundle {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := b.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+func TestVerify(t *testing.T) {
+	cases := []struct {
+		name             string
+		bundle           func() *Bundle
+		key              string
+		requireSignature bool
+		wantErr          string
+	}{
+		{name: "assinado", bundle: func() *Bundle { return newTestBundle("k") }, key: "k"},
+		{name: "sem assinatura e sem chave", bundle: func() *Bundle { return newTestBundle("") }},
+		{name: "sem assinatura com --require-signature", bundle: func() *Bundle { return newTestBundle("") }, requireSignature: true, wantErr: "sem assinatura"},
+		{name: "assinatura removida com chave", key: "k", wantErr: "sem assinatura", bundle: func() *Bundle {
+			b := newTestBundle("k")
+			b.Signature = nil
+			return b
+		}},
+		{name: "chave errada", bundle: func() *Bundle { return newTestBundle("k") }, key: "outra", wantErr: "assinatura do bundle inválida"},
+		{name: "assinado sem chave", bundle: func() *Bundle { return newTestBundle("k") }, wantErr: "informe a chave"},
+		{name: "payload alterado", key: "k", wantErr: "foi alterado", bundle: func() *Bundle {
+			b := newTestBundle("k")
+			b.Entries[0].Payload = json.RawMessage(strings.Replace(testPayload, "Biopass", "Outro", 1))
+			return b
+		}},
+		{name: "payload alterado com hash recalculado", key: "k", wantErr: "assinatura do bundle inválida", bundle: func() *Bundle {
+			b := newTestBundle("k")
+			payload := strings.Replace(testPayload, "Biopass", "Outro", 1)
+			b.Entries[0].Payload = json.RawMessage(payload)
+			b.Entries[0].PayloadSHA256 = payloadHash([]byte(payload))
+			return b
+		}},
+		{name: "aplicação trocada", key: "k", wantErr: "não corresponde", bundle: func() *Bundle {
+			b := newTestBundle("k")
+			b.Entries[0].Application = "outra-app"
+			return b
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := roundTrip(t, tc.bundle()).Verify(tc.key, tc.requireSignature)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("esperava bundle válido: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("esperava erro contendo %q, obteve %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuthenticateRequest é o corpo enviado ao endpoint /v1/authenticate
+type AuthenticateRequest struct {
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	ApplicationCode string `json:"application_code,omitempty"`
+}
+
+// AuthenticateResponse representa a resposta do endpoint /v1/authenticate
+// O servidor pode informar a expiração em expires_at, expires_in ou apenas no claim exp do JWT
+type AuthenticateResponse struct {
+	Token       string    `json:"token"`
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ExpiresIn   int64     `json:"expires_in"` // Segundos
+}
+
+// Authenticate obtém um token JWT com email e senha
+// A requisição não é assinada (nem repetida): as credenciais do usuário já a autenticam
+// Retorna o token e sua expiração (zero se desconhecida)
+func (c *AuthClient) Authenticate(ctx context.Context, creds AuthenticateRequest) (string, time.Time, error) {
+	payload, err := json.Marshal(creds)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("erro ao serializar credenciais: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/v1/authenticate", payload)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	body, err := c.execute(req, payload)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var authResp AuthenticateResponse
+	if err := json.Unmarshal(body, &authResp); err != nil {
+		return "", time.Time{}, fmt.Errorf("erro ao fazer parse da resposta: %w", err)
+	}
+
+	token := authResp.Token
+	if token == "" {
+		token = authResp.AccessToken
+	}
+	if token == "" {
+		return "", time.Time{}, fmt.Errorf("resposta de /v1/authenticate sem token")
+	}
+
+	expiresAt := authResp.ExpiresAt
+	if expiresAt.IsZero() && authResp.ExpiresIn > 0 {
+		expiresAt = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
+	}
+	if expiresAt.IsZero() {
+		expiresAt = TokenExpiry(token)
+	}
+	return token, expiresAt, nil
+}
+
+// TokenExpiry lê o claim exp de um JWT (sem validar a assinatura)
+// Retorna zero se o token não for um JWT ou não tiver exp
+func TokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
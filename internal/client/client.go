@@ -1,18 +1,17 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/redact"
 )
 
 // AuthClient é o cliente HTTP para comunicação com o sagep-auth
@@ -21,6 +20,13 @@ type AuthClient struct {
 	Token      string
 	Secret     string // Secret para HMAC (bootstrap)
 	HTTPClient *http.Client
+	Retry      RetryPolicy
+
+	SignatureVersion string // Esquema HMAC: "v1" (padrão) ou "v2" (requisição canônica + nonce)
+	KeyID            string // Identificador do secret (X-Key-Id), para rotação de chaves
+
+	// OnRetry é chamado antes de cada nova tentativa (opcional, para logs)
+	OnRetry func(attempt int, wait time.Duration, err error)
 }
 
 // NewAuthClient cria uma nova instância do AuthClient
@@ -32,30 +38,60 @@ func NewAuthClient(baseURL, token, secret string) *AuthClient {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		Retry: DefaultRetryPolicy(),
 	}
 }
 
+// Ações retornadas pelo servidor para cada item sincronizado
+// O servidor ignora itens com erro e continua com o próximo (ver docs/REGRAS_NEGOCIO.md)
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionError   = "error"   // Item não processado por erro (Message traz o motivo)
+	ActionSkipped = "skipped" // Item ignorado pelo servidor (Message traz o motivo)
+
+	ActionDeactivated = "deactivated" // Usuário fora do manifest desativado (removed_users)
+	ActionUnlinked    = "unlinked"    // Usuário fora do manifest desvinculado da aplicação (removed_users)
+)
+
+// Ações pedidas ao servidor para usuários vinculados que saíram do manifest (sync --users=authoritative)
+const (
+	RemoveDeactivate = "deactivate" // Desativa o usuário (active = false), mantendo vínculo e roles
+	RemoveUnlink     = "unlink"     // Remove o vínculo com a aplicação e as roles nela
+)
+
+// RemovedUser é um usuário vinculado à aplicação que não está mais no manifest
+type RemovedUser struct {
+	Email  string `json:"email" yaml:"email"`
+	Action string `json:"action" yaml:"action"` // RemoveDeactivate ou RemoveUnlink
+}
+
 // SyncResultDTO representa o resultado de uma operação de sync
 type SyncResultDTO struct {
-	Code   string `json:"code"`
-	Action string `json:"action"` // "created" ou "updated"
-	ID     string `json:"id,omitempty"`
+	Code    string `json:"code" yaml:"code"`
+	Action  string `json:"action" yaml:"action"` // "created", "updated", "error" ou "skipped"
+	ID      string `json:"id,omitempty" yaml:"id,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 // SyncRoleResultDTO representa o resultado de sync de uma role
 type SyncRoleResultDTO struct {
-	Code        string         `json:"code"`
-	Action      string         `json:"action"` // "created" ou "updated"
-	ID          string         `json:"id,omitempty"`
-	Permissions []SyncResultDTO `json:"permissions"`
+	Code        string          `json:"code" yaml:"code"`
+	Action      string          `json:"action" yaml:"action"` // "created", "updated", "error" ou "skipped"
+	ID          string          `json:"id,omitempty" yaml:"id,omitempty"`
+	Message     string          `json:"message,omitempty" yaml:"message,omitempty"`
+	Permissions []SyncResultDTO `json:"permissions" yaml:"permissions"`
 }
 
 // SyncResponse representa a resposta do endpoint /v1/applications/sync
 type SyncResponse struct {
-	Application SyncResultDTO       `json:"application"`
-	Permissions []SyncResultDTO     `json:"permissions"`
-	Roles       []SyncRoleResultDTO `json:"roles"`
-	Users       []SyncResultDTO     `json:"users,omitempty"`
+	Application SyncResultDTO       `json:"application" yaml:"application"`
+	Permissions []SyncResultDTO     `json:"permissions" yaml:"permissions"`
+	Roles       []SyncRoleResultDTO `json:"roles" yaml:"roles"`
+	Users       []SyncResultDTO     `json:"users,omitempty" yaml:"users,omitempty"`
+
+	// Resultado de removed_users: Action "deactivated", "unlinked" ou "error"
+	RemovedUsers []SyncResultDTO `json:"removed_users,omitempty" yaml:"removed_users,omitempty"`
 }
 
 // calculateHMAC calcula a assinatura HMAC do body + timestamp
@@ -66,55 +102,82 @@ func calculateHMAC(body []byte, timestamp int64, secret string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// SyncApplication envia o manifest para o endpoint de sync do sagep-auth
-func (c *AuthClient) SyncApplication(ctx context.Context, m *manifest.AuthManifest) (*SyncResponse, error) {
-	// Converter manifest para JSON
-	payload, err := json.Marshal(m)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao serializar manifest: %w", err)
-	}
+// SyncPayload é o corpo enviado ao endpoint de sync quando apenas algumas seções
+// são sincronizadas. Sections informa ao servidor quais seções são autoritativas;
+// seções ausentes não devem ser alteradas
+type SyncPayload struct {
+	Application manifest.Application  `json:"application"`
+	Permissions []manifest.Permission `json:"permissions,omitempty"`
+	Roles       []manifest.Role       `json:"roles,omitempty"`
+	Users       []manifest.User       `json:"users,omitempty"`
+	Sections    []string              `json:"sections"`
+
+	// RemovedUsers lista usuários vinculados fora do manifest (sync --users=authoritative)
+	RemovedUsers []RemovedUser `json:"removed_users,omitempty"`
+}
 
-	// Construir requisição
-	url := c.BaseURL + "/v1/applications/sync"
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
-	}
+// BuildSyncPayload serializa o manifest para o endpoint de sync
+// sections nil envia o manifest completo (formato original)
+func BuildSyncPayload(m *manifest.AuthManifest, sections []string) ([]byte, error) {
+	return BuildSyncPayloadWithRemovals(m, sections, nil)
+}
 
-	// Headers
-	req.Header.Set("Content-Type", "application/json")
-	
-	// Autenticação: HMAC (obrigatório) OU JWT (opcional, quando disponível)
-	if c.Token != "" {
-		// Se tem token, usar JWT (uso normal)
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	} else if c.Secret != "" {
-		// Usar HMAC (bootstrap) - Secret é obrigatório, então sempre terá
-		timestamp := time.Now().Unix()
-		signature := calculateHMAC(payload, timestamp, c.Secret)
-		req.Header.Set("X-Signature", signature)
-		req.Header.Set("X-Timestamp", fmt.Sprintf("%d", timestamp))
-	} else {
-		// Este caso não deveria acontecer, pois LoadConfig valida isso antes
-		return nil, fmt.Errorf("SAGEP_AUTH_SECRET é obrigatório")
+// BuildSyncPayloadWithRemovals serializa o manifest com os usuários a remover
+// Com remoções, o payload sempre usa o formato com sections
+func BuildSyncPayloadWithRemovals(m *manifest.AuthManifest, sections []string, removed []RemovedUser) ([]byte, error) {
+	if sections == nil && len(removed) == 0 {
+		return json.Marshal(m)
+	}
+	if sections == nil {
+		sections = manifest.AllSections
 	}
 
-	// Executar requisição
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao executar requisição: %w", err)
+	p := SyncPayload{
+		Application:  m.Application,
+		Sections:     sections,
+		RemovedUsers: removed,
+	}
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		p.Permissions = m.Permissions
+	}
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		p.Roles = m.Roles
 	}
-	defer resp.Body.Close()
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		p.Users = m.Users
+	}
+	return json.Marshal(p)
+}
+
+// SyncApplication envia o manifest completo para o endpoint de sync do sagep-auth
+func (c *AuthClient) SyncApplication(ctx context.Context, m *manifest.AuthManifest) (*SyncResponse, error) {
+	return c.SyncSections(ctx, m, nil)
+}
+
+// SyncSections envia apenas as seções informadas do manifest (nil = todas)
+// Falhas transitórias (erros de rede, 5xx e 429) são repetidas conforme c.Retry;
+// todas as tentativas levam o mesmo Idempotency-Key (hash do payload)
+func (c *AuthClient) SyncSections(ctx context.Context, m *manifest.AuthManifest, sections []string) (*SyncResponse, error) {
+	return c.SyncWithRemovals(ctx, m, sections, nil)
+}
 
-	// Ler resposta
-	body, err := io.ReadAll(resp.Body)
+// SyncWithRemovals envia as seções do manifest e os usuários a desativar/desvincular
+func (c *AuthClient) SyncWithRemovals(ctx context.Context, m *manifest.AuthManifest, sections []string, removed []RemovedUser) (*SyncResponse, error) {
+	// Converter manifest para JSON
+	payload, err := BuildSyncPayloadWithRemovals(m, sections, removed)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao ler resposta: %w", err)
+		return nil, fmt.Errorf("erro ao serializar manifest: %w", err)
 	}
 
-	// Verificar status code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("erro na API (status %d): %s", resp.StatusCode, string(body))
+	return c.SendSyncPayload(ctx, payload)
+}
+
+// SendSyncPayload envia um payload de sync já serializado (ex: de um bundle offline)
+// O payload é enviado byte a byte, sem reserialização
+func (c *AuthClient) SendSyncPayload(ctx context.Context, payload []byte) (*SyncResponse, error) {
+	body, err := c.doWithRetry(ctx, "POST", "/v1/applications/sync", payload)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fazer unmarshal da resposta
@@ -122,7 +185,27 @@ func (c *AuthClient) SyncApplication(ctx context.Context, m *manifest.AuthManife
 	if err := json.Unmarshal(body, &syncResp); err != nil {
 		return nil, fmt.Errorf("erro ao fazer parse da resposta: %w", err)
 	}
+	syncResp.scrub(c.knownSecrets(payload))
 
 	return &syncResp, nil
 }
 
+// scrub remove das mensagens por item os segredos ecoados pelo servidor
+func (r *SyncResponse) scrub(known []string) {
+	r.Application.Message = redact.Text(r.Application.Message, known...)
+	for i := range r.Permissions {
+		r.Permissions[i].Message = redact.Text(r.Permissions[i].Message, known...)
+	}
+	for i := range r.Roles {
+		r.Roles[i].Message = redact.Text(r.Roles[i].Message, known...)
+		for j := range r.Roles[i].Permissions {
+			r.Roles[i].Permissions[j].Message = redact.Text(r.Roles[i].Permissions[j].Message, known...)
+		}
+	}
+	for i := range r.Users {
+		r.Users[i].Message = redact.Text(r.Users[i].Message, known...)
+	}
+	for i := range r.RemovedUsers {
+		r.RemovedUsers[i].Message = redact.Text(r.RemovedUsers[i].Message, known...)
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/redact"
+)
+
+// RetryPolicy define quantas vezes e com qual intervalo uma requisição é repetida
+type RetryPolicy struct {
+	MaxRetries int           // Tentativas extras após a primeira (0 = sem retry)
+	BaseDelay  time.Duration // Intervalo inicial do backoff exponencial
+	MaxDelay   time.Duration // Intervalo máximo entre tentativas
+}
+
+// DefaultRetryPolicy retorna a política padrão: 3 retries, 500ms → 30s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// backoff calcula o intervalo antes da tentativa attempt (1 = primeiro retry)
+// Exponencial com jitter: metade fixa + metade aleatória do intervalo
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// APIError representa uma resposta não-2xx do sagep-auth
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // Valor do header Retry-After (0 se ausente)
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("erro na API (status %d): %s", e.StatusCode, e.Body)
+}
+
+// retryable indica se o erro é transitório: 5xx, 429 ou falha de rede que pode passar
+// (timeout, conexão recusada/resetada, EOF). Erros de TLS/certificado, URL inválida ou
+// montagem da requisição não mudam com retry e falham na hora.
+// A decisão de parar vem do contexto de quem chamou: o timeout por requisição do
+// http.Client também aparece como context.DeadlineExceeded e deve ser repetido
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return transientNetworkError(err)
+}
+
+// transientNetworkError reconhece as falhas de rede que costumam passar sozinhas
+func transientNetworkError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE, syscall.ETIMEDOUT} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// idempotencyKey deriva a chave de idempotência do hash do payload
+func idempotencyKey(method, path string, payload []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// doWithRetry executa a requisição com retries, retornando o body da resposta 2xx
+// A autenticação (HMAC com timestamp atual) é recalculada a cada tentativa
+func (c *AuthClient) doWithRetry(ctx context.Context, method, path string, payload []byte) ([]byte, error) {
+	key := idempotencyKey(method, path, payload)
+
+	for attempt := 0; ; attempt++ {
+		body, err := c.do(ctx, method, path, payload, key)
+		if err == nil {
+			return body, nil
+		}
+		if attempt >= c.Retry.MaxRetries || !retryable(ctx, err) {
+			return nil, err
+		}
+
+		wait := c.Retry.backoff(attempt + 1)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			// Retry-After alto (ou malicioso) não pode segurar o CLI além do MaxDelay
+			wait = apiErr.RetryAfter
+			if c.Retry.MaxDelay > 0 && wait > c.Retry.MaxDelay {
+				wait = c.Retry.MaxDelay
+			}
+		}
+		if c.OnRetry != nil {
+			c.OnRetry(attempt+1, wait, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// do executa uma única tentativa da requisição
+func (c *AuthClient) do(ctx context.Context, method, path string, payload []byte, key string) ([]byte, error) {
+	req, err := c.newRequest(ctx, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Idempotency-Key", key)
+
+	// Autenticação: HMAC (obrigatório) OU JWT (opcional, quando disponível)
+	if c.Token != "" {
+		// Se tem token, usar JWT (uso normal)
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else if c.Secret != "" {
+		// Usar HMAC (bootstrap) - timestamp novo a cada tentativa para não ser rejeitado como expirado
+		if err := c.signRequest(req, payload); err != nil {
+			return nil, err
+		}
+	} else {
+		// Este caso não deveria acontecer, pois LoadConfig valida isso antes
+		return nil, fmt.Errorf("SAGEP_AUTH_SECRET é obrigatório")
+	}
+
+	return c.execute(req, payload)
+}
+
+// newRequest cria a requisição JSON para o path informado
+func (c *AuthClient) newRequest(ctx context.Context, method, path string, payload []byte) (*http.Request, error) {
+	// Construir requisição
+	url := c.BaseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	// Headers
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// execute envia a requisição e retorna o body da resposta 2xx (ou *APIError)
+// O body de erro é limpo de segredos: o servidor pode ecoar o payload (senhas) na resposta
+func (c *AuthClient) execute(req *http.Request, payload []byte) ([]byte, error) {
+	// Executar requisição
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Ler resposta
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler resposta: %w", err)
+	}
+
+	// Verificar status code
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       redact.Text(string(body), c.knownSecrets(payload)...),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return body, nil
+}
+
+// knownSecrets retorna os valores que nunca devem aparecer em mensagens de erro
+func (c *AuthClient) knownSecrets(payload []byte) []string {
+	return append(redact.Values(payload), c.Secret, c.Token)
+}
+
+// parseRetryAfter interpreta o header Retry-After (segundos ou data HTTP)
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
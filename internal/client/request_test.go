@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// retryServer responde com statuses[i] na tentativa i (200 depois da lista) e registra as chaves de idempotência
+type retryServer struct {
+	mu       sync.Mutex
+	statuses []int
+	delays   []time.Duration
+	headers  map[string]string
+	keys     []string
+}
+
+func (s *retryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	attempt := len(s.keys)
+	s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+	s.mu.Unlock()
+
+	if attempt < len(s.delays) && s.delays[attempt] > 0 {
+		select {
+		case <-time.After(s.delays[attempt]):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	status := http.StatusOK
+	if attempt < len(s.statuses) {
+		status = s.statuses[attempt]
+	}
+	for k, v := range s.headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(`{}`))
+}
+
+func (s *retryServer) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+func newRetryClient(url string) *AuthClient {
+	c := NewAuthClient(url, "", "secret")
+	c.Retry = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 20 * time.Millisecond}
+	return c
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	srv := &retryServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := newRetryClient(ts.URL)
+	if _, err := c.doWithRetry(context.Background(), http.MethodPost, "/v1/applications/sync", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("esperava sucesso após retries: %v", err)
+	}
+	if srv.attempts() != 3 {
+		t.Fatalf("esperava 3 tentativas, obteve %d", srv.attempts())
+	}
+	for _, key := range srv.keys {
+		if key == "" || key != srv.keys[0] {
+			t.Fatalf("Idempotency-Key deve ser a mesma em todas as tentativas: %v", srv.keys)
+		}
+	}
+}
+
+func TestRetryTooManyRequestsCapsRetryAfter(t *testing.T) {
+	srv := &retryServer{statuses: []int{http.StatusTooManyRequests}, headers: map[string]string{"Retry-After": "3600"}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := newRetryClient(ts.URL)
+	var waits []time.Duration
+	c.OnRetry = func(attempt int, wait time.Duration, err error) { waits = append(waits, wait) }
+
+	if _, err := c.doWithRetry(context.Background(), http.MethodPost, "/x", nil); err != nil {
+		t.Fatalf("esperava sucesso após 429: %v", err)
+	}
+	if len(waits) != 1 || waits[0] > c.Retry.MaxDelay {
+		t.Fatalf("Retry-After deve ser limitado a MaxDelay (%s): %v", c.Retry.MaxDelay, waits)
+	}
+}
+
+func TestRetryRequestTimeout(t *testing.T) {
+	srv := &retryServer{delays: []time.Duration{time.Second}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := newRetryClient(ts.URL)
+	c.HTTPClient.Timeout = 50 * time.Millisecond
+
+	if _, err := c.doWithRetry(context.Background(), http.MethodGet, "/x", nil); err != nil {
+		t.Fatalf("timeout da tentativa deveria ser repetido: %v", err)
+	}
+	if srv.attempts() != 2 {
+		t.Fatalf("esperava 2 tentativas, obteve %d", srv.attempts())
+	}
+}
+
+func TestRetryStops(t *testing.T) {
+	t.Run("4xx", func(t *testing.T) {
+		srv := &retryServer{statuses: []int{http.StatusBadRequest}}
+		ts := httptest.NewServer(srv)
+		defer ts.Close()
+
+		_, err := newRetryClient(ts.URL).doWithRetry(context.Background(), http.MethodGet, "/x", nil)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			t.Fatalf("esperava APIError 400, obteve %v", err)
+		}
+		if srv.attempts() != 1 {
+			t.Fatalf("4xx não deve ser repetido: %d tentativas", srv.attempts())
+		}
+	})
+
+	t.Run("contexto cancelado", func(t *testing.T) {
+		srv := &retryServer{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+		ts := httptest.NewServer(srv)
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c := newRetryClient(ts.URL)
+		c.OnRetry = func(int, time.Duration, error) { cancel() }
+
+		if _, err := c.doWithRetry(ctx, http.MethodGet, "/x", nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("esperava context.Canceled, obteve %v", err)
+		}
+		if srv.attempts() != 1 {
+			t.Fatalf("contexto cancelado não deve gerar nova tentativa: %d tentativas", srv.attempts())
+		}
+	})
+	t.Run("certificado TLS inválido", func(t *testing.T) {
+		ts := httptest.NewTLSServer(&retryServer{})
+		defer ts.Close()
+
+		c := newRetryClient(ts.URL)
+		retries := 0
+		c.OnRetry = func(int, time.Duration, error) { retries++ }
+		if _, err := c.doWithRetry(context.Background(), http.MethodGet, "/x", nil); err == nil {
+			t.Fatal("esperava erro de verificação do certificado")
+		}
+		if retries != 0 {
+			t.Fatalf("erro de TLS não deve ser repetido: %d retries", retries)
+		}
+	})
+
+	t.Run("URL inválida", func(t *testing.T) {
+		c := newRetryClient("http://[::1")
+		retries := 0
+		c.OnRetry = func(int, time.Duration, error) { retries++ }
+		if _, err := c.doWithRetry(context.Background(), http.MethodGet, "/x", nil); err == nil {
+			t.Fatal("esperava erro de URL inválida")
+		}
+		if retries != 0 {
+			t.Fatalf("URL inválida não deve ser repetida: %d retries", retries)
+		}
+	})
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	ts := httptest.NewServer(&retryServer{})
+	url := ts.URL
+	ts.Close()
+
+	c := newRetryClient(url)
+	retries := 0
+	c.OnRetry = func(int, time.Duration, error) { retries++ }
+	if _, err := c.doWithRetry(context.Background(), http.MethodGet, "/x", nil); err == nil {
+		t.Fatal("esperava erro de conexão")
+	}
+	if retries != c.Retry.MaxRetries {
+		t.Fatalf("conexão recusada deve ser repetida %d vezes, obteve %d", c.Retry.MaxRetries, retries)
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versões do esquema de assinatura HMAC (header X-Signature-Version)
+const (
+	// SignatureV1 assina body + timestamp (compatível com servidores antigos)
+	SignatureV1 = "v1"
+	// SignatureV2 assina a requisição canônica: método, host, path, timestamp, nonce e SHA-256 do body
+	SignatureV2 = "v2"
+)
+
+// ParseSignatureVersion valida a versão informada (vazio = v1)
+func ParseSignatureVersion(version string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(version)) {
+	case "", SignatureV1:
+		return SignatureV1, nil
+	case SignatureV2:
+		return SignatureV2, nil
+	}
+	return "", fmt.Errorf("versão de assinatura inválida %q (válidas: v1, v2)", version)
+}
+
+// CanonicalRequest monta a string assinada no esquema v2
+// Formato (uma linha por campo):
+//
+//	MÉTODO
+//	host (minúsculo)
+//	path?query
+//	timestamp (unix, segundos)
+//	nonce
+//	SHA-256 do body (hex)
+func CanonicalRequest(method, host, path string, timestamp int64, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		strings.ToLower(host),
+		path,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// SignCanonical calcula o HMAC-SHA256 (hex) da requisição canônica
+func SignCanonical(canonical, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newNonce gera um nonce aleatório de 128 bits
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// signRequest adiciona os headers de assinatura HMAC à requisição
+// Chamado a cada tentativa, para que timestamp e nonce sejam sempre novos
+func (c *AuthClient) signRequest(req *http.Request, payload []byte) error {
+	timestamp := time.Now().Unix()
+	if c.KeyID != "" {
+		req.Header.Set("X-Key-Id", c.KeyID)
+	}
+
+	version, err := ParseSignatureVersion(c.SignatureVersion)
+	if err != nil {
+		return err
+	}
+
+	switch version {
+	case SignatureV2:
+		nonce, err := newNonce()
+		if err != nil {
+			return err
+		}
+		path := req.URL.EscapedPath()
+		if req.URL.RawQuery != "" {
+			path += "?" + req.URL.RawQuery
+		}
+		canonical := CanonicalRequest(req.Method, req.URL.Host, path, timestamp, nonce, payload)
+		req.Header.Set("X-Signature-Version", SignatureV2)
+		req.Header.Set("X-Nonce", nonce)
+		req.Header.Set("X-Signature", SignCanonical(canonical, c.Secret))
+	default:
+		// v1: sem X-Signature-Version, para servidores que não conhecem o header
+		req.Header.Set("X-Signature", calculateHMAC(payload, timestamp, c.Secret))
+	}
+	req.Header.Set("X-Timestamp", fmt.Sprintf("%d", timestamp))
+	return nil
+}
+
+// VerifySignature verifica a assinatura HMAC (v1 ou v2) de uma requisição recebida
+// Usado pelo servidor fake; o secret é escolhido pelo chamador a partir de X-Key-Id
+// maxSkew limita a diferença entre X-Timestamp e o relógio local
+func VerifySignature(r *http.Request, body []byte, secret string, maxSkew time.Duration) error {
+	signature := r.Header.Get("X-Signature")
+	timestampHeader := r.Header.Get("X-Timestamp")
+	if signature == "" || timestampHeader == "" {
+		return fmt.Errorf("headers X-Signature e X-Timestamp são obrigatórios")
+	}
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("X-Timestamp inválido: %s", timestampHeader)
+	}
+	if skew := time.Since(time.Unix(timestamp, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("X-Timestamp fora da janela permitida (%s)", maxSkew)
+	}
+
+	version, err := ParseSignatureVersion(r.Header.Get("X-Signature-Version"))
+	if err != nil {
+		return err
+	}
+
+	var expected string
+	switch version {
+	case SignatureV2:
+		nonce := r.Header.Get("X-Nonce")
+		if nonce == "" {
+			return fmt.Errorf("header X-Nonce é obrigatório na assinatura v2")
+		}
+		path := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+		expected = SignCanonical(CanonicalRequest(r.Method, r.Host, path, timestamp, nonce, body), secret)
+	default:
+		expected = calculateHMAC(body, timestamp, secret)
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return fmt.Errorf("assinatura HMAC %s inválida", version)
+	}
+	return nil
+}
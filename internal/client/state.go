@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// PermissionState é uma permission cadastrada no servidor
+type PermissionState struct {
+	ID          string `json:"id" yaml:"id"`
+	Code        string `json:"code" yaml:"code"`
+	Subject     string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Action      string `json:"action,omitempty" yaml:"action,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Conditions  string `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// RoleState é uma role cadastrada no servidor, com as permissions já expandidas (sem wildcards)
+type RoleState struct {
+	ID          string   `json:"id" yaml:"id"`
+	Code        string   `json:"code" yaml:"code"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	System      bool     `json:"system" yaml:"system"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// UserState é um usuário vinculado à aplicação, com as roles que tem nela
+type UserState struct {
+	ID       string   `json:"id" yaml:"id"`
+	Email    string   `json:"email" yaml:"email"`
+	Name     string   `json:"name" yaml:"name"`
+	Active   bool     `json:"active" yaml:"active"`
+	TenantID *string  `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Roles    []string `json:"roles" yaml:"roles"`
+}
+
+// ApplicationState representa a resposta de GET /v1/applications/{code}
+type ApplicationState struct {
+	Application struct {
+		ID          string `json:"id" yaml:"id"`
+		Code        string `json:"code" yaml:"code"`
+		Name        string `json:"name" yaml:"name"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	} `json:"application" yaml:"application"`
+	Permissions []PermissionState `json:"permissions" yaml:"permissions"`
+	Roles       []RoleState       `json:"roles" yaml:"roles"`
+	Users       []UserState       `json:"users" yaml:"users"`
+}
+
+// GetApplication lê o estado atual da aplicação no servidor
+// Aplicação inexistente retorna *APIError com StatusCode 404
+func (c *AuthClient) GetApplication(ctx context.Context, code string) (*ApplicationState, error) {
+	body, err := c.doWithRetry(ctx, "GET", "/v1/applications/"+url.PathEscape(code), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var state ApplicationState
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse da resposta: %w", err)
+	}
+	return &state, nil
+}
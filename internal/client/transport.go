@@ -0,0 +1,125 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Valores padrão de conexão
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultConnectTimeout = 10 * time.Second
+)
+
+// ProxyNone desabilita o proxy (ignora HTTP_PROXY/HTTPS_PROXY)
+const ProxyNone = "none"
+
+// HTTPOptions configura TLS, timeouts e proxy do http.Client usado pelo AuthClient
+type HTTPOptions struct {
+	CACert             string // PEM com CA(s) adicionais para verificar o servidor (ex: CA interna)
+	ClientCert         string // Certificado PEM do cliente (mTLS)
+	ClientKey          string // Chave privada PEM do cliente (mTLS)
+	InsecureSkipVerify bool   // Não verifica o certificado do servidor (apenas para diagnóstico)
+
+	Timeout        time.Duration // Tempo máximo de cada requisição (0 = DefaultTimeout)
+	ConnectTimeout time.Duration // Tempo máximo de conexão + handshake TLS (0 = DefaultConnectTimeout)
+
+	// Proxy: vazio usa HTTP_PROXY/HTTPS_PROXY/NO_PROXY; "none" desabilita; senão, URL do proxy
+	Proxy string
+}
+
+// NewHTTPClient cria o http.Client conforme as opções de TLS, timeouts e proxy
+func NewHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = DefaultConnectTimeout
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}, nil
+}
+
+// buildTLSConfig monta a configuração TLS (CA adicional, certificado de cliente, skip verify)
+func buildTLSConfig(opts HTTPOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler CA %s: %w", opts.CACert, err)
+		}
+		// CA adicional às do sistema (a CA interna não substitui as públicas)
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("nenhum certificado PEM válido em %s", opts.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (opts.ClientCert == "") != (opts.ClientKey == "") {
+		return nil, fmt.Errorf("certificado e chave do cliente devem ser informados juntos (--client-cert e --client-key)")
+	}
+	if opts.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar certificado do cliente %s / %s: %w", opts.ClientCert, opts.ClientKey, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// proxyFunc interpreta a opção de proxy
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch strings.ToLower(strings.TrimSpace(proxy)) {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyNone:
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy inválido %q (ex: http://proxy.interno:3128 ou %q)", proxy, ProxyNone)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// serverCA grava o certificado do httptest.Server como CA em PEM
+func serverCA(t *testing.T, ts *httptest.Server) string {
+	return writePEM(t, "ca.pem", "CERTIFICATE", ts.Certificate().Raw)
+}
+
+// clientCertificate gera uma CA de clientes e um certificado assinado por ela
+// Retorna o pool da CA (para o servidor) e os arquivos do certificado e da chave (para o cliente)
+func clientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sagep-auth clientes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "sagep-auth-cli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, writePEM(t, "client.crt", "CERTIFICATE", der), writePEM(t, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+}
+
+func get(t *testing.T, opts HTTPOptions, url string) error {
+	t.Helper()
+	c, err := NewHTTPClient(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestHTTPClientCustomCA(t *testing.T) {
+	ts := httptest.NewTLSServer(okHandler())
+	defer ts.Close()
+
+	if err := get(t, HTTPOptions{Proxy: ProxyNone}, ts.URL); err == nil {
+		t.Fatal("sem a CA, o certificado do servidor não deveria ser aceito")
+	}
+	if err := get(t, HTTPOptions{Proxy: ProxyNone, CACert: serverCA(t, ts)}, ts.URL); err != nil {
+		t.Fatalf("com a CA, a conexão deveria funcionar: %v", err)
+	}
+	if err := get(t, HTTPOptions{Proxy: ProxyNone, InsecureSkipVerify: true}, ts.URL); err != nil {
+		t.Fatalf("com skip verify, a conexão deveria funcionar: %v", err)
+	}
+}
+
+func TestHTTPClientMutualTLS(t *testing.T) {
+	pool, certFile, keyFile := clientCertificate(t)
+
+	ts := httptest.NewUnstartedServer(okHandler())
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	ts.StartTLS()
+	defer ts.Close()
+	ca := serverCA(t, ts)
+
+	if err := get(t, HTTPOptions{Proxy: ProxyNone, CACert: ca}, ts.URL); err == nil {
+		t.Fatal("sem certificado de cliente, o servidor deveria recusar a conexão")
+	}
+	if err := get(t, HTTPOptions{Proxy: ProxyNone, CACert: ca, ClientCert: certFile, ClientKey: keyFile}, ts.URL); err != nil {
+		t.Fatalf("com certificado de cliente, a conexão deveria funcionar: %v", err)
+	}
+}
+
+func TestBuildTLSConfigErrors(t *testing.T) {
+	_, certFile, _ := clientCertificate(t)
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("não é PEM"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]HTTPOptions{
+		"CA inexistente":        {CACert: filepath.Join(t.TempDir(), "ausente.pem")},
+		"CA sem PEM":            {CACert: invalid},
+		"certificado sem chave": {ClientCert: certFile},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewHTTPClient(opts); err == nil {
+				t.Fatal("esperava erro")
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/bundle"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// RunSyncBundle grava em opts.Bundle o payload que o sync enviaria, sem contatar o servidor
+// Com opts.BundleKey, o bundle é assinado (HMAC-SHA256) para ser verificado no apply-bundle
+func RunSyncBundle(manifestPaths []string, opts SyncOptions) error {
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+
+	// A prévia dos usuários a remover depende do estado do servidor
+	if opts.Users == UsersAuthoritative {
+		return fmt.Errorf("--users=authoritative não é suportado com --bundle (a lista de usuários a remover depende do servidor)")
+	}
+
+	// Mesmas validações do sync: nenhum bundle com manifest inválido
+	targets, err := loadSyncTargets(paths, opts.Sections)
+	if err != nil {
+		return err
+	}
+	if err := checkPasswordPolicy(targets, opts.Sections); err != nil {
+		return err
+	}
+	if err := checkTenants(targets, opts.Sections, opts.TenantsPath); err != nil {
+		return err
+	}
+
+	b := bundle.New()
+	for _, t := range targets {
+		for _, warning := range t.Warnings {
+			fmt.Printf("⚠️  %s: %s\n", t.Manifest.Application.Code, warning)
+		}
+		payload, err := client.BuildSyncPayload(t.Manifest, opts.Sections)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar %s: %w", t.Path, err)
+		}
+		b.Add(t.Manifest.Application.Code, t.Path, t.Manifest.Hash(), opts.Sections, payload)
+	}
+
+	if opts.BundleKey != "" {
+		b.Sign(opts.BundleKey, opts.BundleKeyID)
+	}
+	if err := b.Write(opts.Bundle); err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 Bundle gravado em %s (%d aplicação(ões))\n", opts.Bundle, len(b.Entries))
+	for _, e := range b.Entries {
+		fmt.Printf("   %s  manifest %s  payload %s\n", e.Application, e.ManifestHash[:12], e.PayloadSHA256[:12])
+	}
+	if b.Signature != nil {
+		fmt.Println("   Assinado (hmac-sha256)")
+	} else {
+		fmt.Println("   ⚠️  Não assinado: use --bundle-key para o destino verificar a origem")
+	}
+	for _, t := range targets {
+		if len(t.Manifest.Users) > 0 && manifest.HasSection(opts.Sections, manifest.SectionUsers) {
+			fmt.Println("   ⚠️  O bundle contém as senhas dos usuários do manifest: trate-o como sensível")
+			break
+		}
+	}
+	return nil
+}
+
+// ApplyBundleOptions contém as opções do comando apply-bundle
+type ApplyBundleOptions struct {
+	BundleKey        string // Chave para verificar a assinatura do bundle
+	RequireSignature bool   // Rejeita bundles sem assinatura
+	Strict           bool   // Falha também quando itens são ignorados ou ausentes na resposta
+	Yes              bool   // Confirma em perfil protegido sem perguntar
+}
+
+// RunApplyBundle verifica o bundle e envia cada payload ao servidor configurado
+// A requisição é autenticada com o secret (ou token) do ambiente de destino
+func RunApplyBundle(path string, cfg *config.Config, opts ApplyBundleOptions) error {
+	b, err := bundle.Read(path)
+	if err != nil {
+		return err
+	}
+	if err := b.Verify(opts.BundleKey, opts.RequireSignature); err != nil {
+		return err
+	}
+
+	targets := make([]*syncTarget, 0, len(b.Entries))
+	payloads := make([][]byte, 0, len(b.Entries))
+	sections := make([][]string, 0, len(b.Entries))
+	for i := range b.Entries {
+		e := &b.Entries[i]
+		payload, err := e.PayloadBytes()
+		if err != nil {
+			return err
+		}
+		m, entrySections, err := manifestFromPayload(payload)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Application, err)
+		}
+		targets = append(targets, &syncTarget{Path: e.ManifestPath, Manifest: m})
+		payloads = append(payloads, payload)
+		sections = append(sections, entrySections)
+	}
+
+	fmt.Printf("Aplicando bundle %s (criado em %s)\n", path, b.CreatedAt.Local().Format("02/01/2006 15:04"))
+	if b.Signature != nil {
+		fmt.Println("Assinatura: válida")
+	} else {
+		fmt.Println("Assinatura: ⚠️  bundle não assinado")
+	}
+	fmt.Printf("URL do auth: %s\n", cfg.AuthURL)
+	if cfg.Profile != config.DefaultProfile {
+		fmt.Printf("Perfil: %s\n", cfg.Profile)
+	}
+	for _, t := range targets {
+		fmt.Printf("  %s (%s)\n", t.Manifest.Application.Code, t.Path)
+	}
+
+	if err := applySavedLogin(cfg, os.Stdout); err != nil {
+		return err
+	}
+	fmt.Println()
+	if err := confirmProtectedProfile(cfg, opts.Yes); err != nil {
+		return err
+	}
+
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Envio sequencial, na ordem do bundle
+	failed := 0
+	for i, t := range targets {
+		start := time.Now()
+		t.Response, t.Err = authClient.SendSyncPayload(context.Background(), payloads[i])
+		t.Duration = time.Since(start)
+		if t.Err == nil {
+			t.Problems = analyzeSyncResponse(t.Manifest, t.Response, sections[i])
+		}
+		if t.failed(opts.Strict) {
+			failed++
+		}
+	}
+
+	if len(targets) > 1 {
+		printBatchReport(targets, opts.Strict)
+	} else if targets[0].Err == nil {
+		printSyncSummary(targets[0].Response, sections[0], targets[0].Problems)
+	}
+
+	if failed > 0 {
+		if len(targets) == 1 {
+			if targets[0].Err == nil {
+				return fmt.Errorf("bundle aplicado com %d item(ns) não sincronizado(s)", len(targets[0].Problems))
+			}
+			return fmt.Errorf("erro ao aplicar bundle: %w", targets[0].Err)
+		}
+		return fmt.Errorf("%d de %d aplicação(ões) falharam ao aplicar o bundle", failed, len(targets))
+	}
+
+	fmt.Println("\nBundle aplicado com sucesso.")
+	return nil
+}
+
+// manifestFromPayload reconstrói o manifest (e as seções) a partir do payload de sync,
+// para comparar com a resposta do servidor
+func manifestFromPayload(payload []byte) (*manifest.AuthManifest, []string, error) {
+	var p client.SyncPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, nil, fmt.Errorf("payload inválido: %w", err)
+	}
+	if strings.TrimSpace(p.Application.Code) == "" {
+		return nil, nil, fmt.Errorf("payload sem application.code")
+	}
+	m := &manifest.AuthManifest{
+		Application: p.Application,
+		Permissions: p.Permissions,
+		Roles:       p.Roles,
+		Users:       p.Users,
+	}
+	if len(p.Sections) == 0 {
+		return m, nil, nil
+	}
+	return m, p.Sections, nil
+}
+
+// RunSyncBundleWithExit executa RunSyncBundle e faz os.Exit apropriado em caso de erro
+func RunSyncBundleWithExit(manifestPaths []string, opts SyncOptions) {
+	if err := RunSyncBundle(manifestPaths, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunApplyBundleWithExit executa RunApplyBundle e faz os.Exit apropriado em caso de erro
+func RunApplyBundleWithExit(path string, cfg *config.Config, opts ApplyBundleOptions) {
+	if err := RunApplyBundle(path, cfg, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+)
+
+// newAuthClient cria o AuthClient a partir da configuração carregada
+// Retries e o aviso de --insecure-skip-verify são informados no stderr para não poluir a saída do comando
+func newAuthClient(cfg *config.Config) (*client.AuthClient, error) {
+	httpClient, err := client.NewHTTPClient(client.HTTPOptions{
+		CACert:             cfg.CACert,
+		ClientCert:         cfg.ClientCert,
+		ClientKey:          cfg.ClientKey,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		Timeout:            cfg.Timeout,
+		ConnectTimeout:     cfg.ConnectTimeout,
+		Proxy:              cfg.Proxy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro na configuração de conexão: %w", err)
+	}
+	if cfg.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "⚠️  ⚠️  ⚠️  INSECURE-SKIP-VERIFY ATIVO: o certificado TLS do servidor NÃO será verificado.")
+		fmt.Fprintln(os.Stderr, "⚠️  Qualquer um no caminho pode interceptar secrets, tokens e senhas. Use apenas para diagnóstico;")
+		fmt.Fprintln(os.Stderr, "⚠️  para uma CA interna, prefira --ca-cert.")
+	}
+
+	authClient := client.NewAuthClient(cfg.AuthURL, cfg.AuthToken, cfg.AuthSecret)
+	authClient.HTTPClient = httpClient
+	authClient.SignatureVersion = cfg.SignatureVersion
+	authClient.KeyID = cfg.KeyID
+	authClient.Retry.MaxRetries = cfg.MaxRetries
+	if cfg.RetryDelay > 0 {
+		authClient.Retry.BaseDelay = cfg.RetryDelay
+	}
+	authClient.OnRetry = func(attempt int, wait time.Duration, err error) {
+		fmt.Fprintf(os.Stderr, "⏳ %v - nova tentativa %d/%d em %s\n", err, attempt, cfg.MaxRetries, wait.Round(time.Millisecond))
+	}
+	return authClient, nil
+}
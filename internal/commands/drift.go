@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/drift"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// Códigos de saída do drift (mesma convenção do terraform plan -detailed-exitcode)
+// Erro tem precedência sobre drift: a verificação ficou incompleta
+const (
+	DriftExitInSync = 0
+	DriftExitError  = 1
+	DriftExitDrift  = 2
+)
+
+// Status do drift por aplicação, ambiente e geral
+const (
+	driftInSync = "in_sync"
+	driftFound  = "drift"
+	driftError  = "error"
+)
+
+// DriftOutputSchemaVersion é a versão do schema de saída json/yaml do drift
+const DriftOutputSchemaVersion = 1
+
+// DriftOptions contém as opções do comando drift
+type DriftOptions struct {
+	Profiles []string // Perfis a verificar (vazio = todos os perfis do config.yaml)
+	Sections []string // Seções comparadas (--only/--skip); nil = todas
+	Output   string   // Formato da saída: table (padrão), json ou yaml
+
+	// LoadConfig carrega a configuração de um perfil ("" = sem perfil: .env e variáveis de ambiente)
+	LoadConfig func(profile string) (*config.Config, error)
+}
+
+// DriftOutput é o documento emitido por 'drift --output json|yaml'
+type DriftOutput struct {
+	SchemaVersion int                `json:"schema_version" yaml:"schema_version"`
+	Status        string             `json:"status" yaml:"status"` // "in_sync", "drift" ou "error"
+	CheckedAt     time.Time          `json:"checked_at" yaml:"checked_at"`
+	Environments  []DriftEnvironment `json:"environments" yaml:"environments"`
+}
+
+// DriftEnvironment é o resultado de um perfil
+type DriftEnvironment struct {
+	Profile      string             `json:"profile" yaml:"profile"`
+	URL          string             `json:"url" yaml:"url"`
+	Status       string             `json:"status" yaml:"status"`
+	Error        string             `json:"error,omitempty" yaml:"error,omitempty"`
+	Applications []DriftApplication `json:"applications" yaml:"applications"`
+}
+
+// DriftApplication é o resultado de um manifest em um perfil
+type DriftApplication struct {
+	Application string             `json:"application" yaml:"application"`
+	Manifest    string             `json:"manifest" yaml:"manifest"`
+	Status      string             `json:"status" yaml:"status"`
+	Error       string             `json:"error,omitempty" yaml:"error,omitempty"`
+	Differences []drift.Difference `json:"differences" yaml:"differences"`
+}
+
+// RunDrift compara o estado do servidor com os manifests em cada perfil configurado
+// Apenas leitura (GET /v1/applications/{code}); retorna o código de saída
+// (DriftExitInSync, DriftExitDrift ou DriftExitError)
+func RunDrift(manifestPaths []string, opts DriftOptions) int {
+	output, err := parseOutputFormat(opts.Output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return DriftExitError
+	}
+	progress := io.Writer(os.Stdout)
+	if output != OutputTable {
+		progress = os.Stderr
+	}
+
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return DriftExitError
+	}
+	targets, err := loadSyncTargets(paths, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return DriftExitError
+	}
+
+	profiles, err := driftProfiles(opts.Profiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return DriftExitError
+	}
+
+	out := DriftOutput{SchemaVersion: DriftOutputSchemaVersion, Status: driftInSync, CheckedAt: time.Now().UTC()}
+	for _, profile := range profiles {
+		env := checkDrift(progress, profile, targets, opts)
+		out.Environments = append(out.Environments, env)
+		switch {
+		case env.Status == driftError:
+			out.Status = driftError
+		case env.Status == driftFound && out.Status == driftInSync:
+			out.Status = driftFound
+		}
+	}
+
+	if output == OutputTable {
+		printDriftReport(out)
+	} else if err := writeDriftOutput(os.Stdout, output, out); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return DriftExitError
+	}
+
+	switch out.Status {
+	case driftError:
+		return DriftExitError
+	case driftFound:
+		return DriftExitDrift
+	}
+	return DriftExitInSync
+}
+
+// driftProfiles retorna os perfis a verificar: os informados ou todos do config.yaml
+// Sem perfis configurados, verifica apenas a configuração do .env / variáveis de ambiente
+func driftProfiles(profiles []string) ([]string, error) {
+	if len(profiles) > 0 {
+		return profiles, nil
+	}
+	pf, _, err := config.LoadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if names := pf.Names(); len(names) > 0 {
+		return names, nil
+	}
+	return []string{""}, nil
+}
+
+// checkDrift compara todos os manifests com o servidor de um perfil
+func checkDrift(progress io.Writer, profile string, targets []*syncTarget, opts DriftOptions) DriftEnvironment {
+	env := DriftEnvironment{Profile: profile, Status: driftInSync, Applications: []DriftApplication{}}
+	if env.Profile == "" {
+		env.Profile = config.DefaultProfile
+	}
+
+	cfg, err := opts.LoadConfig(profile)
+	if err == nil {
+		env.Profile = cfg.Profile
+		env.URL = cfg.AuthURL
+		err = applySavedLogin(cfg, progress)
+	}
+	var authClient *client.AuthClient
+	if err == nil {
+		authClient, err = newAuthClient(cfg)
+	}
+	if err != nil {
+		env.Status = driftError
+		env.Error = err.Error()
+		return env
+	}
+
+	fmt.Fprintf(progress, "Verificando %s (%s)...\n", env.Profile, env.URL)
+	for _, t := range targets {
+		app := DriftApplication{Application: t.Manifest.Application.Code, Manifest: t.Path, Status: driftInSync}
+		state, err := authClient.GetApplication(context.Background(), t.Manifest.Application.Code)
+		var apiErr *client.APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			app.Differences = drift.Missing(t.Manifest)
+		case err != nil:
+			app.Status = driftError
+			app.Error = err.Error()
+		default:
+			app.Differences = drift.Compare(t.Manifest, state, opts.Sections)
+		}
+		if app.Differences == nil {
+			app.Differences = []drift.Difference{}
+		}
+
+		switch {
+		case app.Status == driftError:
+			env.Status = driftError
+		case len(app.Differences) > 0:
+			app.Status = driftFound
+			if env.Status == driftInSync {
+				env.Status = driftFound
+			}
+		}
+		env.Applications = append(env.Applications, app)
+	}
+	return env
+}
+
+// printDriftReport exibe as diferenças por perfil e aplicação
+func printDriftReport(out DriftOutput) {
+	counts := map[string]int{}
+	for _, env := range out.Environments {
+		counts[env.Status]++
+		fmt.Printf("\nPerfil %s (%s)\n", env.Profile, env.URL)
+		if env.Error != "" {
+			fmt.Printf("  ❌ %s\n", env.Error)
+			continue
+		}
+		for _, app := range env.Applications {
+			switch app.Status {
+			case driftError:
+				fmt.Printf("  ❌ %s: %s\n", app.Application, strings.TrimSpace(app.Error))
+				continue
+			case driftInSync:
+				fmt.Printf("  ✅ %s: em sincronia\n", app.Application)
+				continue
+			}
+			fmt.Printf("  ⚠️  %s: %d diferença(s)\n", app.Application, len(app.Differences))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, d := range app.Differences {
+				id := ""
+				if d.ID != "" {
+					id = " (id " + d.ID + ")"
+				}
+				fmt.Fprintf(w, "     %s\t%s\t%s%s\n", d.Section, d.Kind, d.Code, id)
+				for _, detail := range d.Details {
+					fmt.Fprintf(w, "     \t\t  %s\n", detail)
+				}
+			}
+			w.Flush()
+		}
+	}
+	fmt.Printf("\nResumo: %d em sincronia, %d com drift, %d com erro\n", counts[driftInSync], counts[driftFound], counts[driftError])
+}
+
+// writeDriftOutput serializa o documento no formato escolhido
+func writeDriftOutput(w io.Writer, format string, out DriftOutput) error {
+	if format == OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// RunDriftWithExit executa RunDrift e sai com o código correspondente
+func RunDriftWithExit(manifestPaths []string, opts DriftOptions) {
+	os.Exit(RunDrift(manifestPaths, opts))
+}
@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/fakeserver"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/lockfile"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+const e2eSecret = "e2e-secret"
+
+const e2eManifest = `apiVersion: sagep-auth/v1
+application:
+  code: sagep-biopass
+  name: Biopass
+permissions:
+  - code: biopass.devices.read
+    subject: biopass.devices
+    action: read
+    description: Listar dispositivos
+roles:
+  - code: biopass.viewer
+    name: Visualizador
+    permissions:
+      - biopass.devices.read
+users:
+  - email: operador@sagep.com.br
+    password: Biometria#Segura2026
+    name: Operador
+    roles:
+      - biopass.viewer
+`
+
+// e2eEnv sobe o servidor fake e grava o manifest em um diretório temporário
+// Perfis, credenciais do login e o auth-manifest.lock ficam isolados no diretório do teste
+func e2eEnv(t *testing.T) (*fakeserver.Server, *config.Config, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("SAGEP_AUTH_CONFIG", filepath.Join(dir, "config.yaml"))
+
+	srv := fakeserver.New(fakeserver.Options{Secret: e2eSecret})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	path := filepath.Join(dir, "auth-manifest.yaml")
+	if err := os.WriteFile(path, []byte(e2eManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{AuthURL: ts.URL, AuthSecret: e2eSecret, Profile: config.DefaultProfile}
+	return srv, cfg, path
+}
+
+func e2eDriftOptions(cfg *config.Config) DriftOptions {
+	return DriftOptions{LoadConfig: func(string) (*config.Config, error) { return cfg, nil }}
+}
+
+func e2eState(t *testing.T, cfg *config.Config) *client.ApplicationState {
+	t.Helper()
+	c, err := newAuthClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := c.GetApplication(context.Background(), "sagep-biopass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestE2ESyncAndDrift(t *testing.T) {
+	_, cfg, path := e2eEnv(t)
+
+	if code := RunDrift([]string{path}, e2eDriftOptions(cfg)); code != DriftExitDrift {
+		t.Fatalf("antes do sync, drift deveria sair com %d, obteve %d", DriftExitDrift, code)
+	}
+	if err := RunSync([]string{path}, cfg, SyncOptions{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if code := RunDrift([]string{path}, e2eDriftOptions(cfg)); code != DriftExitInSync {
+		t.Fatalf("depois do sync, drift deveria sair com %d, obteve %d", DriftExitInSync, code)
+	}
+
+	state := e2eState(t, cfg)
+	if len(state.Users) != 1 || !state.Users[0].Active || strings.Join(state.Users[0].Roles, ",") != "biopass.viewer" {
+		t.Fatalf("usuário não sincronizado como esperado: %+v", state.Users)
+	}
+
+	// Permission nova no manifest: drift volta a acusar diferença até o próximo sync
+	updated := strings.Replace(e2eManifest, "roles:\n  - code", `  - code: biopass.devices.delete
+    subject: biopass.devices
+    action: delete
+    description: Remover dispositivos
+roles:
+  - code`, 1)
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := RunDrift([]string{path}, e2eDriftOptions(cfg)); code != DriftExitDrift {
+		t.Fatalf("permission nova deveria gerar drift, obteve %d", code)
+	}
+	if err := RunSync([]string{path}, cfg, SyncOptions{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if code := RunDrift([]string{path}, e2eDriftOptions(cfg)); code != DriftExitInSync {
+		t.Fatalf("depois do segundo sync, drift deveria sair com %d, obteve %d", DriftExitInSync, code)
+	}
+}
+
+func TestE2ESyncUsersAuthoritative(t *testing.T) {
+	srv, cfg, path := e2eEnv(t)
+
+	m, err := manifest.LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Users = append(m.Users, manifest.User{Email: "ex-operador@sagep.com.br", Password: "Leitor#Digital2025", Name: "Ex-operador", Roles: []string{"biopass.viewer"}})
+	if _, err := srv.Apply(m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		action string
+		check  func(state *client.ApplicationState) bool
+	}{
+		{client.RemoveDeactivate, func(state *client.ApplicationState) bool {
+			return len(state.Users) == 2 && !userState(state, "ex-operador@sagep.com.br").Active
+		}},
+		{client.RemoveUnlink, func(state *client.ApplicationState) bool {
+			return len(state.Users) == 1 && state.Users[0].Email == "operador@sagep.com.br"
+		}},
+	} {
+		opts := SyncOptions{Users: UsersAuthoritative, RemovedUsers: tc.action, Yes: true}
+		if err := RunSync([]string{path}, cfg, opts); err != nil {
+			t.Fatalf("sync --users=authoritative --removed-users=%s: %v", tc.action, err)
+		}
+		if state := e2eState(t, cfg); !tc.check(state) {
+			t.Fatalf("--removed-users=%s: estado inesperado %+v", tc.action, state.Users)
+		}
+		if !userState(e2eState(t, cfg), "operador@sagep.com.br").Active {
+			t.Fatalf("--removed-users=%s: usuário do manifest deveria continuar ativo", tc.action)
+		}
+		// Usuário desativado (ou desvinculado) não é drift: o ambiente volta a ficar em sincronia
+		if code := RunDrift([]string{path}, e2eDriftOptions(cfg)); code != DriftExitInSync {
+			t.Fatalf("--removed-users=%s: drift deveria sair com %d, obteve %d", tc.action, DriftExitInSync, code)
+		}
+	}
+}
+
+func userState(state *client.ApplicationState, email string) client.UserState {
+	for _, u := range state.Users {
+		if u.Email == email {
+			return u
+		}
+	}
+	return client.UserState{}
+}
+
+func TestE2ESyncSendsPasswordRotation(t *testing.T) {
+	_, cfg, path := e2eEnv(t)
+	login := func(password string) error {
+		t.Helper()
+		c, err := newAuthClient(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = c.Authenticate(context.Background(), client.AuthenticateRequest{Email: "operador@sagep.com.br", Password: password, ApplicationCode: "sagep-biopass"})
+		return err
+	}
+
+	if err := RunSync([]string{path}, cfg, SyncOptions{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	// Apenas a senha muda: o hash do lock é o mesmo, mas o sync precisa enviá-la
+	const rotated = "Leitor#Digital2025"
+	if err := os.WriteFile(path, []byte(strings.Replace(e2eManifest, "Biometria#Segura2026", rotated, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunSync([]string{path}, cfg, SyncOptions{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := login(rotated); err != nil {
+		t.Fatalf("a senha trocada deveria ter sido enviada: %v", err)
+	}
+
+	// Sem mudanças, o estado local permite pular a aplicação no próximo sync
+	statePath, err := config.PasswordStatePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(statePath)
+	if err != nil {
+		t.Fatalf("estado local de senhas não gravado: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("estado de senhas deve ter permissão 0600, tem %v", info.Mode().Perm())
+	}
+	state, err := lockfile.LoadPasswordState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := lockfile.EnvironmentKey(cfg.Profile, cfg.AuthURL)
+	if !state.Unchanged(env, m) {
+		t.Error("as senhas sincronizadas deveriam estar registradas no estado local")
+	}
+	m.Users[0].Password = "Outra#Senha2027"
+	if state.Unchanged(env, m) {
+		t.Error("uma nova troca de senha deveria ser detectada")
+	}
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), rotated) {
+		t.Fatal("o estado local não pode conter a senha")
+	}
+	lock, err := os.ReadFile(filepath.Join(filepath.Dir(path), "auth-manifest.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(lock), rotated) {
+		t.Fatal("o lock não pode conter a senha")
+	}
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+// KeygenOptions contém as opções do comando keygen
+type KeygenOptions struct {
+	Output string // Arquivo de chaves (acrescenta ao final); "-" = stdout; vazio = arquivo padrão
+	AES    bool   // Gera uma chave simétrica AES-256 em vez de X25519
+}
+
+// EncryptOptions contém as opções do comando encrypt
+type EncryptOptions struct {
+	Recipients     []string // Chaves públicas (sagep-x25519:...) ou simétricas (SAGEP-AES256-KEY:...)
+	RecipientsFile string   // Arquivo com um destinatário por linha
+	KeyFiles       []string // Sem destinatários, criptografa para as chaves destes arquivos
+}
+
+// DecryptOptions contém as opções do comando decrypt
+type DecryptOptions struct {
+	KeyFiles []string // Arquivos de chaves (padrão: SAGEP_AUTH_KEY_FILE, SAGEP_AUTH_KEY ou o arquivo padrão)
+	InPlace  bool     // Regrava o manifest em texto claro em vez de imprimir no stdout
+}
+
+// RunKeygen gera uma chave e a acrescenta ao arquivo de chaves (permissão 0600)
+// A chave pública (X25519) é exibida para ser usada em 'encrypt --recipient'
+func RunKeygen(opts KeygenOptions) error {
+	var key, recipient, comment string
+	if opts.AES {
+		k, err := secrets.GenerateAES()
+		if err != nil {
+			return err
+		}
+		key, recipient = k.String(), ""
+		comment = fmt.Sprintf("# id: %s (chave simétrica: quem tem a chave criptografa e decifra)\n", k.ID())
+	} else {
+		k, err := secrets.GenerateX25519()
+		if err != nil {
+			return err
+		}
+		key, recipient = k.String(), k.Recipient().String()
+		comment = fmt.Sprintf("# recipient: %s\n", recipient)
+	}
+	entry := fmt.Sprintf("# created: %s\n%s%s\n", time.Now().Format(time.RFC3339), comment, key)
+
+	if opts.Output == "-" {
+		fmt.Print(entry)
+		return nil
+	}
+
+	path := opts.Output
+	if path == "" {
+		var err error
+		if path, err = secrets.DefaultKeyFile(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("erro ao criar diretório: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de chaves: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(entry); err != nil {
+		return fmt.Errorf("erro ao gravar chave: %w", err)
+	}
+
+	fmt.Printf("🔑 Chave gravada em %s\n", path)
+	if recipient != "" {
+		fmt.Printf("   Chave pública (use em encrypt --recipient): %s\n", recipient)
+	} else {
+		fmt.Println("   ⚠️  Chave simétrica: compartilhe o arquivo apenas com quem pode decifrar")
+	}
+	return nil
+}
+
+// RunEncrypt criptografa in-place os campos password e !sensitive dos manifests
+// Valores já criptografados são mantidos; comentários e formatação são preservados
+func RunEncrypt(manifestPaths []string, opts EncryptOptions) error {
+	recipients, err := encryptRecipients(opts)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		ids = append(ids, r.ID())
+	}
+
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		doc, err := manifest.LoadDocument(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		count, err := doc.Encrypt(recipients)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if count == 0 {
+			fmt.Printf("✅ %s: nada a criptografar\n", path)
+			continue
+		}
+		if err := doc.Save(path); err != nil {
+			return err
+		}
+		fmt.Printf("🔒 %s: %d valor(es) criptografado(s) para %s\n", path, count, strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+// encryptRecipients reúne os destinatários de --recipient e --recipients-file
+// Sem nenhum, usa as chaves públicas das chaves locais
+func encryptRecipients(opts EncryptOptions) ([]secrets.Recipient, error) {
+	var recipients []secrets.Recipient
+	for _, s := range opts.Recipients {
+		r, err := secrets.ParseRecipient(s)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	if opts.RecipientsFile != "" {
+		data, err := os.ReadFile(opts.RecipientsFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler destinatários: %w", err)
+		}
+		rs, err := secrets.ParseRecipients(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opts.RecipientsFile, err)
+		}
+		recipients = append(recipients, rs...)
+	}
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+
+	identities, err := secrets.LoadIdentities(opts.KeyFiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range identities {
+		recipients = append(recipients, id.Recipient())
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("nenhum destinatário: use --recipient, --recipients-file ou gere uma chave com 'keygen'")
+	}
+	return recipients, nil
+}
+
+// RunDecrypt decifra os valores ENC[...] de um manifest
+// Por padrão imprime o resultado no stdout; --in-place regrava o arquivo
+func RunDecrypt(manifestPath string, opts DecryptOptions) error {
+	identities, err := secrets.LoadIdentities(opts.KeyFiles)
+	if err != nil {
+		return err
+	}
+	if len(identities) == 0 {
+		return fmt.Errorf("nenhuma chave: use --key-file, SAGEP_AUTH_KEY_FILE ou SAGEP_AUTH_KEY")
+	}
+
+	doc, err := manifest.LoadDocument(manifestPath)
+	if err != nil {
+		return err
+	}
+	count, err := doc.Decrypt(identities)
+	if err != nil {
+		return err
+	}
+
+	if !opts.InPlace {
+		data, err := doc.Bytes()
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+		return nil
+	}
+	if count == 0 {
+		fmt.Printf("✅ %s: nenhum valor criptografado\n", manifestPath)
+		return nil
+	}
+	if err := doc.Save(manifestPath); err != nil {
+		return err
+	}
+	fmt.Printf("🔓 %s: %d valor(es) decifrado(s)\n", manifestPath, count)
+	fmt.Println("   ⚠️  O arquivo está em texto claro: execute 'encrypt' antes do commit")
+	return nil
+}
+
+// RunKeygenWithExit executa RunKeygen e faz os.Exit apropriado em caso de erro
+func RunKeygenWithExit(opts KeygenOptions) {
+	if err := RunKeygen(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunEncryptWithExit executa RunEncrypt e faz os.Exit apropriado em caso de erro
+func RunEncryptWithExit(manifestPaths []string, opts EncryptOptions) {
+	if err := RunEncrypt(manifestPaths, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunDecryptWithExit executa RunDecrypt e faz os.Exit apropriado em caso de erro
+func RunDecryptWithExit(manifestPath string, opts DecryptOptions) {
+	if err := RunDecrypt(manifestPath, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/graph"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// GraphOptions contém as opções do comando graph
+type GraphOptions struct {
+	Format string       // dot ou mermaid (padrão: pela extensão de Output; sem Output, dot)
+	Output string       // Arquivo de saída (vazio = stdout); .md gera um bloco ```mermaid
+	Filter graph.Filter // Restringe o grafo por role, subject e/ou usuário
+}
+
+// RunGraph exporta users → roles → permissions → subjects dos manifests (não contata o servidor)
+func RunGraph(manifestPaths []string, opts GraphOptions) error {
+	format, fenced, err := graphFormat(opts.Format, opts.Output)
+	if err != nil {
+		return err
+	}
+
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+	manifests := make([]*manifest.AuthManifest, 0, len(paths))
+	for _, path := range paths {
+		m, err := manifest.LoadManifestWithoutKeys(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		manifests = append(manifests, m)
+	}
+
+	g := graph.Build(manifests, opts.Filter)
+	if g.Empty() {
+		return fmt.Errorf("nenhum caminho corresponde aos filtros informados")
+	}
+
+	var buf bytes.Buffer
+	if format == graph.FormatMermaid {
+		err = graph.WriteMermaid(&buf, g, fenced)
+	} else {
+		err = graph.WriteDOT(&buf, g)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao gerar grafo: %w", err)
+	}
+
+	if opts.Output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return nil
+	}
+	if err := os.WriteFile(opts.Output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("erro ao gravar grafo: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "🕸️  Grafo gravado em %s (%d aplicação(ões))\n", opts.Output, len(g.Clusters))
+	return nil
+}
+
+// graphFormat resolve o formato: --format explícito ou extensão do arquivo
+// Em arquivos .md o Mermaid vai dentro de um bloco ```mermaid
+func graphFormat(format, output string) (string, bool, error) {
+	ext := strings.ToLower(filepath.Ext(output))
+	fenced := ext == ".md"
+	if format == "" {
+		switch ext {
+		case ".mmd", ".mermaid", ".md":
+			return graph.FormatMermaid, fenced, nil
+		}
+		return graph.FormatDOT, false, nil
+	}
+	switch strings.ToLower(format) {
+	case graph.FormatDOT, "gv":
+		return graph.FormatDOT, false, nil
+	case graph.FormatMermaid, "mmd":
+		return graph.FormatMermaid, fenced, nil
+	}
+	return "", false, fmt.Errorf("formato inválido %q (válidos: dot, mermaid)", format)
+}
+
+// RunGraphWithExit executa RunGraph e faz os.Exit apropriado em caso de erro
+func RunGraphWithExit(manifestPaths []string, opts GraphOptions) {
+	if err := RunGraph(manifestPaths, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
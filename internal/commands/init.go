@@ -7,6 +7,7 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
 	"gopkg.in/yaml.v3"
 )
 
@@ -94,7 +95,39 @@ type RoleAnswer struct {
 	Permissions []string
 }
 
-func RunInit(manifestPath string) error {
+// InitOptions contém as opções do comando init
+type InitOptions struct {
+	GeneratePasswords bool   // Gera senhas fortes para os novos usuários em vez de perguntar
+	PasswordsFile     string // Arquivo (0600) com as senhas geradas; vazio = exibe uma única vez no stdout
+
+	// Destinatários das senhas gravadas no manifest (--recipient/--recipients-file)
+	// Obrigatórios com --generate-passwords; sem eles, usa as chaves locais (ver 'keygen')
+	Encrypt EncryptOptions
+}
+
+// generatedPassword é uma senha gerada por init --generate-passwords
+type generatedPassword struct {
+	Email    string
+	Password string
+}
+
+// RunInit executa o wizard interativo de criação/edição do manifest
+// O glossário (glossaryPath ou glossary.yaml ao lado do manifest) traduz os nomes de entidades
+func RunInit(manifestPath, glossaryPath string, opts InitOptions) error {
+	glossary, err := manifest.LoadGlossaryFor(glossaryPath, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// Verificar antes do wizard: o arquivo de senhas nunca é sobrescrito
+	if opts.PasswordsFile != "" {
+		if _, err := os.Stat(opts.PasswordsFile); err == nil {
+			return fmt.Errorf("arquivo de senhas %s já existe (não será sobrescrito)", opts.PasswordsFile)
+		}
+	}
+	var generated []generatedPassword
+	var recipients []secrets.Recipient
+
 	// Verificar se manifest já existe
 	var existingManifest *manifest.AuthManifest
 	manifestExists := false
@@ -112,6 +145,23 @@ func RunInit(manifestPath string) error {
 			}
 		} else {
 			existingManifest = loaded
+			// Manifest criptografado: as senhas voltam criptografadas para os mesmos destinatários
+			doc, err := manifest.LoadDocument(manifestPath)
+			if err != nil {
+				return err
+			}
+			if doc.HasEncrypted() {
+				if recipients, err = sameRecipients(doc, opts.Encrypt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Senhas geradas nunca vão em texto claro para o manifest: resolver os destinatários antes do wizard
+	if recipients == nil && (opts.GeneratePasswords || len(opts.Encrypt.Recipients) > 0 || opts.Encrypt.RecipientsFile != "") {
+		if recipients, err = encryptRecipients(opts.Encrypt); err != nil {
+			return fmt.Errorf("as senhas são gravadas criptografadas no manifest: %w", err)
 		}
 	}
 
@@ -201,10 +251,10 @@ func RunInit(manifestPath string) error {
 		}
 		
 		fmt.Println("\n📝 Modo: Adicionar recursos ao manifest existente")
-		fmt.Println("═══════════════════════════════════════════════════════\n")
+		fmt.Print("═══════════════════════════════════════════════════════\n\n")
 	} else {
 		fmt.Println("\n🚀 Criando novo manifest para integração com sagep-auth")
-		fmt.Println("═══════════════════════════════════════════════════════\n")
+		fmt.Print("═══════════════════════════════════════════════════════\n\n")
 	}
 
 	// 1. Informações da Aplicação (apenas se criando novo manifest)
@@ -290,6 +340,7 @@ func RunInit(manifestPath string) error {
 	}
 
 	if answers.CreateUsers {
+		policy := existingManifest.EffectivePasswordPolicy()
 		for {
 			var user UserAnswer
 			
@@ -311,13 +362,19 @@ func RunInit(manifestPath string) error {
 					},
 					Validate: survey.Required,
 				},
-				{
+			}
+			// Com --generate-passwords a senha não é perguntada
+			if !opts.GeneratePasswords {
+				userQuestions = append(userQuestions, &survey.Question{
 					Name: "password",
 					Prompt: &survey.Password{
 						Message: "Senha:",
+						Help:    passwordPolicyHelp(policy),
 					},
-					Validate: survey.Required,
-				},
+					Validate: survey.ComposeValidators(survey.Required, passwordValidator(policy)),
+				})
+			}
+			userQuestions = append(userQuestions, []*survey.Question{
 				{
 					Name: "name",
 					Prompt: &survey.Input{
@@ -332,12 +389,22 @@ func RunInit(manifestPath string) error {
 						Help:    "Opcional - deixe vazio para usuário global. Especialmente útil para primeiro usuário/bootstrap (ex: unidade-005)",
 					},
 				},
-			}
+			}...)
 
 			if err := survey.Ask(userQuestions, &user); err != nil {
 				break
 			}
 
+			if opts.GeneratePasswords {
+				if user.Password, err = policy.GeneratePassword(); err != nil {
+					return err
+				}
+				generated = append(generated, generatedPassword{Email: user.Email, Password: user.Password})
+				fmt.Println("   🔑 Senha gerada (exibida ao final)")
+			} else if problems := policy.Check(user.Password, user.Email); len(problems) > 0 {
+				fmt.Printf("   ⚠️  Senha não atende a password_policy: %s\n", strings.Join(problems, ", "))
+			}
+
 			// Limpar tenant_id se vazio (para não incluir no YAML)
 			user.TenantID = strings.TrimSpace(user.TenantID)
 
@@ -380,7 +447,7 @@ func RunInit(manifestPath string) error {
 	}
 
 	if answers.CreatePermissions {
-		fmt.Println("\n💡 Você pode criar permissões de Menu ou de Recurso (entidade).\n")
+		fmt.Print("\n💡 Você pode criar permissões de Menu ou de Recurso (entidade).\n\n")
 
 		for {
 			var perm PermissionAnswer
@@ -455,7 +522,15 @@ func RunInit(manifestPath string) error {
 				actionValue := extractActionValue(resourceInput.Action)
 				
 				// Inferir automaticamente usando appCode
-				code, subject, actionOut := manifest.InferResourcePermission(resourceInput.Entidade, actionValue, answers.AppCode)
+				code, subject, actionOut := manifest.InferResourcePermissionWithGlossary(resourceInput.Entidade, actionValue, answers.AppCode, glossary)
+				// Se o manifest existente declara conventions, seguir o padrão declarado
+				entidade := glossary.Canonical(resourceInput.Entidade)
+				if code != "" && existingManifest != nil && existingManifest.Conventions != nil {
+					code, subject = existingManifest.EffectiveConventions().ResourcePermission(entidade, actionOut, answers.AppCode)
+				}
+				if entidade != manifest.Slugify(resourceInput.Entidade) {
+					fmt.Printf("\n   📖 Glossário: %s → %s\n", strings.TrimSpace(resourceInput.Entidade), entidade)
+				}
 				perm.Code = code
 				perm.Subject = subject
 				perm.Action = actionOut
@@ -718,13 +793,73 @@ func RunInit(manifestPath string) error {
 
 	// Criar manifest a partir das respostas
 	m := buildManifestFromAnswers(answers)
+	if existingManifest != nil {
+		m.Conventions = existingManifest.Conventions
+		m.PasswordPolicy = existingManifest.PasswordPolicy
+	}
 
 	// Salvar arquivo
-	return saveManifest(m, manifestPath)
+	if err := saveManifest(m, manifestPath, recipients); err != nil {
+		return err
+	}
+	return deliverGeneratedPasswords(generated, opts.PasswordsFile)
+}
+
+// passwordValidator valida a senha digitada no wizard contra a política
+// O email ainda não é conhecido aqui; a verificação completa é feita após as perguntas
+func passwordValidator(policy manifest.PasswordPolicy) survey.Validator {
+	return func(ans interface{}) error {
+		password, _ := ans.(string)
+		if problems := policy.Check(password, ""); len(problems) > 0 {
+			return fmt.Errorf("senha fraca: %s", strings.Join(problems, ", "))
+		}
+		return nil
+	}
+}
+
+// passwordPolicyHelp descreve a política no help do prompt de senha
+func passwordPolicyHelp(policy manifest.PasswordPolicy) string {
+	help := fmt.Sprintf("Mínimo de %d caracteres", policy.MinLength)
+	if len(policy.Require) > 0 {
+		help += ", com " + strings.Join(policy.Require, ", ")
+	}
+	return help + ". Senhas de exemplo (ex: Master@123) são rejeitadas. Use --generate-passwords para gerar senhas fortes"
+}
+
+// deliverGeneratedPasswords entrega as senhas geradas uma única vez:
+// em um arquivo novo com permissão 0600 ou no stdout
+func deliverGeneratedPasswords(generated []generatedPassword, path string) error {
+	if len(generated) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("email,password\n")
+	for _, g := range generated {
+		sb.WriteString(g.Email + "," + g.Password + "\n")
+	}
+
+	if path == "" {
+		fmt.Println("\n🔑 Senhas geradas (exibidas apenas esta vez; entregue-as por um canal seguro):")
+		fmt.Print(sb.String())
+		return nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo de senhas: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo de senhas: %w", err)
+	}
+	fmt.Printf("\n🔑 %d senha(s) gerada(s) gravada(s) em %s (permissão 0600)\n", len(generated), path)
+	return nil
 }
 
 func buildManifestFromAnswers(answers InitAnswers) *manifest.AuthManifest {
 	m := &manifest.AuthManifest{
+		APIVersion:  manifest.CurrentAPIVersion,
 		Application: manifest.Application{
 			Code:        answers.AppCode,
 			Name:        answers.AppName,
@@ -782,7 +917,19 @@ func buildManifestFromAnswers(answers InitAnswers) *manifest.AuthManifest {
 	return m
 }
 
-func saveManifest(m *manifest.AuthManifest, path string) error {
+func saveManifest(m *manifest.AuthManifest, path string, recipients []secrets.Recipient) error {
+	var root yaml.Node
+	if err := root.Encode(m); err != nil {
+		return fmt.Errorf("erro ao escrever YAML: %w", err)
+	}
+	encrypted := 0
+	if len(recipients) > 0 {
+		var err error
+		if encrypted, err = secrets.EncryptTree(&root, recipients); err != nil {
+			return err
+		}
+	}
+
 	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("erro ao criar arquivo: %w", err)
@@ -792,7 +939,7 @@ func saveManifest(m *manifest.AuthManifest, path string) error {
 	encoder := yaml.NewEncoder(file)
 	encoder.SetIndent(2)
 	
-	if err := encoder.Encode(m); err != nil {
+	if err := encoder.Encode(&root); err != nil {
 		return fmt.Errorf("erro ao escrever YAML: %w", err)
 	}
 
@@ -802,8 +949,82 @@ func saveManifest(m *manifest.AuthManifest, path string) error {
 	fmt.Printf("   - Permissões: %d\n", len(m.Permissions))
 	fmt.Printf("   - Roles: %d\n", len(m.Roles))
 	fmt.Printf("   - Usuários: %d\n", len(m.Users))
+	if encrypted > 0 {
+		fmt.Printf("\n🔒 %d senha(s) criptografada(s) no manifest (ENC[...])\n", encrypted)
+	} else if hasPlaintextPassword(m) {
+		fmt.Println("\n⚠️  Há senhas em texto claro no manifest: execute 'encrypt' antes do commit")
+	}
 	fmt.Printf("\n🚀 Próximo passo: Execute 'sync' para sincronizar com o servidor\n")
 
 	return nil
 }
 
+// sameRecipients retorna os destinatários dos valores ENC[...] do manifest (chaves locais
+// ou informadas em --recipient/--recipients-file), para o init regravar as senhas
+// criptografadas para as mesmas chaves; destinatários informados nas flags são acrescentados
+func sameRecipients(doc *manifest.Document, opts EncryptOptions) ([]secrets.Recipient, error) {
+	fields, err := doc.EncryptedFields()
+	if err != nil {
+		return nil, err
+	}
+	needed := make(map[string]bool)
+	var ids []string
+	for _, f := range fields {
+		// O init regrava o manifest a partir da struct e perderia a tag !sensitive
+		if !f.Password {
+			return nil, fmt.Errorf("%s é um campo !sensitive criptografado, que o init não preserva: edite o manifest com 'decrypt' e 'encrypt'", f.Path)
+		}
+		for _, id := range f.Recipients {
+			if !needed[id] {
+				needed[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	var explicit []secrets.Recipient
+	if len(opts.Recipients) > 0 || opts.RecipientsFile != "" {
+		if explicit, err = encryptRecipients(opts); err != nil {
+			return nil, err
+		}
+	}
+	identities, err := secrets.LoadIdentities(opts.KeyFiles)
+	if err != nil {
+		return nil, err
+	}
+	candidates := explicit
+	for _, id := range identities {
+		candidates = append(candidates, id.Recipient())
+	}
+
+	var recipients []secrets.Recipient
+	found := make(map[string]bool)
+	for i, r := range candidates {
+		if found[r.ID()] || (i >= len(explicit) && !needed[r.ID()]) {
+			continue
+		}
+		found[r.ID()] = true
+		recipients = append(recipients, r)
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("o manifest tem senhas criptografadas para %s: informe as chaves públicas de %s com --recipient ou --recipients-file para o init gravá-las criptografadas para os mesmos destinatários", strings.Join(ids, ", "), strings.Join(missing, ", "))
+	}
+	fmt.Printf("\n🔒 Manifest criptografado: as senhas serão gravadas criptografadas para %s\n", strings.Join(ids, ", "))
+	return recipients, nil
+}
+
+// hasPlaintextPassword indica se algum usuário tem senha fora do formato ENC[...]
+func hasPlaintextPassword(m *manifest.AuthManifest) bool {
+	for _, u := range m.Users {
+		if u.Password != "" && !secrets.IsEncrypted(u.Password) {
+			return true
+		}
+	}
+	return false
+}
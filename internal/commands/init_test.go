@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+func TestSaveManifestEncryptsPasswords(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	key, err := secrets.GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const password = "Gerada#Forte2026xyz"
+	m := buildManifestFromAnswers(InitAnswers{
+		AppCode: "sagep-biopass",
+		AppName: "Biopass",
+		Roles:   []RoleAnswer{{Code: "master", Name: "Master", System: true}},
+		Users:   []UserAnswer{{Email: "master@sagep.com.br", Password: password, Name: "Master", Roles: []string{"master"}}},
+	})
+	path := filepath.Join(dir, "auth-manifest.yaml")
+	if err := saveManifest(m, path, []secrets.Recipient{key.Recipient()}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), password) || !strings.Contains(string(data), "ENC[") {
+		t.Fatalf("a senha deveria estar criptografada no manifest:\n%s", data)
+	}
+
+	t.Setenv("SAGEP_AUTH_KEY", key.String())
+	loaded, err := manifest.LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Users[0].Password != password {
+		t.Fatalf("senha decifrada diferente: %q", loaded.Users[0].Password)
+	}
+}
+
+func TestSameRecipients(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("SAGEP_AUTH_KEY_FILE", "")
+	local, err := secrets.GenerateX25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := secrets.GenerateX25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SAGEP_AUTH_KEY", local.String())
+
+	write := func(content string) *manifest.Document {
+		t.Helper()
+		path := filepath.Join(dir, "auth-manifest.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := manifest.LoadDocument(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := doc.Encrypt([]secrets.Recipient{local.Recipient(), other.Recipient()}); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	doc := write("users:\n  - email: master@sagep.com.br\n    password: Senha#DoMaster2026\n")
+	if _, err := sameRecipients(doc, EncryptOptions{}); err == nil || !strings.Contains(err.Error(), other.ID()) {
+		t.Fatalf("sem a chave pública do outro destinatário, esperava erro citando %s: %v", other.ID(), err)
+	}
+	recipients, err := sameRecipients(doc, EncryptOptions{Recipients: []string{other.Recipient().String()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 2 {
+		t.Fatalf("esperava os 2 destinatários do manifest, obteve %d", len(recipients))
+	}
+
+	doc = write("users:\n  - email: master@sagep.com.br\n    name: !sensitive Master\n")
+	if _, err := sameRecipients(doc, EncryptOptions{Recipients: []string{other.Recipient().String()}}); err == nil || !strings.Contains(err.Error(), "!sensitive") {
+		t.Fatalf("campo !sensitive criptografado deveria ser recusado: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// RunLint verifica se as permissions seguem o bloco conventions do manifest
+// O glossário (glossaryPath ou glossary.yaml ao lado do manifest) traduz nomes de entidades
+// Com fix=true, reescreve code/subject divergentes (preservando comentários do YAML)
+// e atualiza as referências nas roles
+func RunLint(manifestPath, glossaryPath string, fix bool) error {
+	m, err := manifest.LoadManifestWithoutKeys(manifestPath)
+	if err != nil {
+		return fmt.Errorf("erro ao carregar manifest: %w", err)
+	}
+
+	glossary, err := manifest.LoadGlossaryFor(glossaryPath, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	issues, err := manifest.Lint(m, glossary)
+	if err != nil {
+		return err
+	}
+
+	conv := m.EffectiveConventions()
+	fmt.Printf("Convenções: subject_style=%s, code_pattern=%s, plurality=%s\n\n", conv.SubjectStyle, conv.CodePattern, conv.Plurality)
+
+	if len(issues) == 0 {
+		fmt.Println("✅ Todas as permissions seguem as convenções.")
+		return nil
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("  ⚠️  %s\n", issue)
+	}
+	fmt.Println()
+
+	if !fix {
+		return fmt.Errorf("%d violação(ões) de convenção encontrada(s). Execute 'lint --fix' para corrigir", len(issues))
+	}
+
+	// Renomear para um code já usado fundiria duas permissions (e suas referências nas roles)
+	fixes, collisions := manifest.CodeCollisions(m, issues)
+	for _, issue := range collisions {
+		fmt.Printf("  ❌ não corrigido: permissions[%d] (%s): o code %q já é usado por outra permission (ou por outra correção)\n", issue.Index, issue.Current, issue.Expected)
+	}
+	if len(collisions) > 0 {
+		fmt.Println()
+	}
+
+	doc, err := manifest.LoadDocument(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	renames := make(map[string]string)
+	for _, issue := range fixes {
+		if err := doc.SetPermissionField(issue.Index, issue.Field, issue.Expected); err != nil {
+			return err
+		}
+		if issue.Field == "code" {
+			renames[issue.Current] = issue.Expected
+		}
+	}
+	renamedRefs := doc.RenamePermissionReferences(renames)
+
+	if len(fixes) > 0 {
+		if err := doc.Save(manifestPath); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %d correção(ões) aplicada(s) em %s", len(fixes), manifestPath)
+		if renamedRefs > 0 {
+			fmt.Printf(" (%d referência(s) em roles atualizada(s))", renamedRefs)
+		}
+		fmt.Println()
+	}
+
+	if len(collisions) > 0 {
+		return fmt.Errorf("%d correção(ões) de code não aplicada(s) por colisão; renomeie manualmente", len(collisions))
+	}
+	return nil
+}
+
+// RunLintWithExit executa RunLint e faz os.Exit apropriado em caso de erro
+func RunLintWithExit(manifestPath, glossaryPath string, fix bool) {
+	if err := RunLint(manifestPath, glossaryPath, fix); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
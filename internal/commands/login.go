@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"golang.org/x/term"
+)
+
+// LoginOptions contém as opções do comando login
+type LoginOptions struct {
+	Email         string // Email do usuário (perguntado se vazio)
+	Password      string // Senha (perguntada se vazia; prefira PasswordStdin)
+	PasswordStdin bool   // Lê a senha da primeira linha do stdin
+	AppCode       string // application_code enviado ao /v1/authenticate (opcional)
+}
+
+// RunLogin obtém um token JWT em /v1/authenticate e o salva nas credenciais do perfil
+// Os próximos comandos usam o token automaticamente enquanto ele não expirar
+func RunLogin(cfg *config.Config, opts LoginOptions) error {
+	email := strings.TrimSpace(opts.Email)
+	password := opts.Password
+
+	if opts.PasswordStdin {
+		if password != "" {
+			return fmt.Errorf("--password e --password-stdin não podem ser usados juntos")
+		}
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("erro ao ler senha do stdin: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+		if password == "" {
+			return fmt.Errorf("nenhuma senha recebida no stdin")
+		}
+	}
+
+	if email == "" {
+		if !isInteractive() {
+			return fmt.Errorf("--email é obrigatório fora de um terminal interativo")
+		}
+		if err := survey.AskOne(&survey.Input{Message: "Email:"}, &email, survey.WithValidator(survey.Required)); err != nil {
+			return fmt.Errorf("erro ao ler email: %w", err)
+		}
+	}
+	if password == "" {
+		if !isInteractive() {
+			return fmt.Errorf("informe a senha com --password-stdin fora de um terminal interativo")
+		}
+		if err := survey.AskOne(&survey.Password{Message: "Senha:"}, &password, survey.WithValidator(survey.Required)); err != nil {
+			return fmt.Errorf("erro ao ler senha: %w", err)
+		}
+	}
+
+	fmt.Printf("Autenticando %s em %s (perfil: %s)\n", email, cfg.AuthURL, cfg.Profile)
+
+	creds, path, err := authenticateAndSave(cfg, email, password, opts.AppCode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Login realizado. Credenciais salvas em %s\n", path)
+	if creds.ExpiresAt.IsZero() {
+		fmt.Println("   Expiração do token desconhecida")
+	} else {
+		fmt.Printf("   Token válido até %s\n", creds.ExpiresAt.Local().Format("02/01/2006 15:04"))
+	}
+	return nil
+}
+
+// authenticateAndSave chama /v1/authenticate e grava o token no perfil de cfg
+func authenticateAndSave(cfg *config.Config, email, password, appCode string) (*config.Credentials, string, error) {
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	token, expiresAt, err := authClient.Authenticate(context.Background(), client.AuthenticateRequest{
+		Email:           email,
+		Password:        password,
+		ApplicationCode: appCode,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao autenticar: %w", err)
+	}
+
+	creds := &config.Credentials{
+		AuthURL:    cfg.AuthURL,
+		Email:      email,
+		AppCode:    appCode,
+		Token:      token,
+		ExpiresAt:  expiresAt,
+		ObtainedAt: time.Now(),
+	}
+	path, err := config.SaveCredentials(cfg.Profile, creds)
+	if err != nil {
+		return nil, "", err
+	}
+	return creds, path, nil
+}
+
+// applySavedLogin usa o token salvo pelo login quando nenhum token foi informado
+// (--token ou SAGEP_AUTH_TOKEN). Token expirado: em terminal interativo, pede a senha
+// novamente; caso contrário (ou se o usuário recusar), segue com HMAC
+// Mensagens vão para progress (stderr quando a saída é json/yaml)
+func applySavedLogin(cfg *config.Config, progress io.Writer) error {
+	if cfg.AuthToken != "" {
+		return nil
+	}
+
+	creds, err := config.LoadCredentials(cfg.Profile)
+	if err != nil {
+		return err
+	}
+	if creds == nil || creds.Token == "" {
+		return nil
+	}
+	if creds.AuthURL != cfg.AuthURL {
+		fmt.Fprintf(progress, "⚠️  Login do perfil %s é de outra URL (%s) - usando HMAC\n", cfg.Profile, creds.AuthURL)
+		return nil
+	}
+
+	if !creds.Expired() {
+		cfg.AuthToken = creds.Token
+		fmt.Fprintf(progress, "Autenticação: JWT de %s (perfil: %s)\n", creds.Email, cfg.Profile)
+		return nil
+	}
+
+	if !isInteractive() {
+		fmt.Fprintf(progress, "⚠️  Token de %s expirou em %s - usando HMAC. Execute 'login' para renovar\n",
+			creds.Email, creds.ExpiresAt.Local().Format("02/01/2006 15:04"))
+		return nil
+	}
+
+	stdio := survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)
+	renew := true
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Token de %s expirou. Fazer login novamente?", creds.Email),
+		Default: true,
+	}, &renew, stdio); err != nil {
+		return fmt.Errorf("erro ao ler resposta: %w", err)
+	}
+	if !renew {
+		fmt.Fprintln(progress, "Usando HMAC")
+		return nil
+	}
+
+	var password string
+	if err := survey.AskOne(&survey.Password{Message: "Senha:"}, &password, survey.WithValidator(survey.Required), stdio); err != nil {
+		return fmt.Errorf("erro ao ler senha: %w", err)
+	}
+	renewed, _, err := authenticateAndSave(cfg, creds.Email, password, creds.AppCode)
+	if err != nil {
+		return err
+	}
+	cfg.AuthToken = renewed.Token
+	fmt.Fprintf(progress, "✅ Login renovado para %s\n", renewed.Email)
+	return nil
+}
+
+// isInteractive indica se o stdin é um terminal (permite prompts)
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// RunLoginWithExit executa RunLogin e faz os.Exit apropriado em caso de erro
+func RunLoginWithExit(cfg *config.Config, opts LoginOptions) {
+	if err := RunLogin(cfg, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
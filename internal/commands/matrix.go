@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/matrix"
+)
+
+// MatrixOptions contém as opções do comando matrix
+type MatrixOptions struct {
+	Format string // csv, html ou markdown (padrão: pela extensão de Output; sem Output, markdown)
+	Output string // Arquivo de saída (vazio = stdout)
+	Users  bool   // Inclui a visão users × roles
+	Table  string // CSV: tabela do arquivo, permissions (padrão) ou users
+}
+
+// RunMatrix gera a matriz roles × permissions do manifest (não contata o servidor)
+func RunMatrix(manifestPath string, opts MatrixOptions) error {
+	format, err := matrixFormat(opts.Format, opts.Output)
+	if err != nil {
+		return err
+	}
+
+	table, err := matrixTable(format, opts)
+	if err != nil {
+		return err
+	}
+
+	m, err := manifest.LoadManifestWithoutKeys(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	mx := matrix.Build(m)
+	var users *matrix.UserMatrix
+	if opts.Users {
+		users = matrix.BuildUsers(m)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case matrix.FormatCSV:
+		if table == matrix.TableUsers {
+			err = matrix.WriteUsersCSV(&buf, matrix.BuildUsers(m))
+		} else {
+			err = matrix.WriteCSV(&buf, mx)
+		}
+	case matrix.FormatHTML:
+		err = matrix.WriteHTML(&buf, mx, users)
+	default:
+		err = matrix.WriteMarkdown(&buf, mx, users)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao gerar matriz: %w", err)
+	}
+
+	if opts.Output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return nil
+	}
+	if err := os.WriteFile(opts.Output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("erro ao gravar matriz: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "📊 Matriz gravada em %s (%d roles, %d permissions)\n", opts.Output, len(m.Roles), len(m.Permissions))
+	return nil
+}
+
+// matrixFormat resolve o formato de saída: --format explícito ou extensão do arquivo
+func matrixFormat(format, output string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(output)) {
+		case ".csv":
+			return matrix.FormatCSV, nil
+		case ".html", ".htm":
+			return matrix.FormatHTML, nil
+		}
+		return matrix.FormatMarkdown, nil
+	}
+	switch strings.ToLower(format) {
+	case matrix.FormatCSV:
+		return matrix.FormatCSV, nil
+	case matrix.FormatHTML:
+		return matrix.FormatHTML, nil
+	case matrix.FormatMarkdown, "md":
+		return matrix.FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("formato inválido %q (válidos: csv, html, markdown)", format)
+}
+
+// matrixTable resolve a tabela do CSV: um arquivo tem uma única tabela, então a visão
+// users × roles é pedida com --table users em vez de --users
+func matrixTable(format string, opts MatrixOptions) (string, error) {
+	if format != matrix.FormatCSV {
+		if opts.Table != "" {
+			return "", fmt.Errorf("--table vale apenas para o formato csv")
+		}
+		return "", nil
+	}
+	if opts.Users {
+		return "", fmt.Errorf("o CSV tem uma tabela por arquivo: use --table users para a visão users × roles")
+	}
+	switch strings.ToLower(opts.Table) {
+	case "", matrix.TablePermissions:
+		return matrix.TablePermissions, nil
+	case matrix.TableUsers:
+		return matrix.TableUsers, nil
+	}
+	return "", fmt.Errorf("tabela inválida %q (válidas: permissions, users)", opts.Table)
+}
+
+// RunMatrixWithExit executa RunMatrix e faz os.Exit apropriado em caso de erro
+func RunMatrixWithExit(manifestPath string, opts MatrixOptions) {
+	if err := RunMatrix(manifestPath, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// MigrateOptions contém as opções do comando migrate
+type MigrateOptions struct {
+	To          string // apiVersion de destino (padrão: a mais recente)
+	DryRun      bool   // Exibe as decisões sem gravar os arquivos
+	RenameRoles bool   // Converte codes de role do formato antigo (BIOPASS_ADMIN → biopass.admin)
+	Force       bool   // Grava mesmo com itens pendentes de revisão manual (⚠️)
+}
+
+// RunMigrate atualiza manifests para a apiVersion pedida, preservando comentários
+// Cada decisão automática é listada; itens sem decisão possível fazem o comando sair com erro
+// e o arquivo não é gravado (gravar carimbaria a apiVersion e esconderia as pendências), salvo com Force
+func RunMigrate(manifestPaths []string, opts MigrateOptions) error {
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+
+	migrateOpts := manifest.MigrateOptions{To: opts.To, RenameRoles: opts.RenameRoles}
+	to := opts.To
+	if to == "" {
+		to = manifest.CurrentAPIVersion
+	}
+
+	migrated, manual, skipped, failed := 0, 0, 0, 0
+	for _, path := range paths {
+		doc, err := manifest.LoadDocument(path)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		from, notes, err := doc.Migrate(migrateOpts)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		if from == to {
+			fmt.Printf("✅ %s: já está em %s\n", path, to)
+			continue
+		}
+
+		fromLabel := from
+		if fromLabel == manifest.APIVersionLegacy {
+			fromLabel = "(sem apiVersion)"
+		}
+		fmt.Printf("🔄 %s: %s → %s\n", path, fromLabel, to)
+		pending := 0
+		for _, note := range notes {
+			if note.Manual {
+				fmt.Printf("   ⚠️  %s\n", note)
+				pending++
+			} else {
+				fmt.Printf("   - %s\n", note)
+			}
+		}
+		manual += pending
+
+		// Com pendências o resultado ainda não valida; fora isso, o arquivo migrado precisa carregar
+		if pending == 0 {
+			if _, err := doc.Manifest(); err != nil {
+				fmt.Printf("   ❌ manifest migrado não é válido: %v\n", err)
+				failed++
+				continue
+			}
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		if pending > 0 && !opts.Force {
+			fmt.Printf("   ⏸️  não gravado: resolva os itens ⚠️ no arquivo e execute migrate de novo (ou use --force)\n")
+			skipped++
+			continue
+		}
+		if err := doc.Save(path); err != nil {
+			fmt.Printf("   ❌ %v\n", err)
+			failed++
+			continue
+		}
+		migrated++
+	}
+
+	fmt.Println()
+	if opts.DryRun {
+		fmt.Println("🔍 --dry-run: nenhum arquivo foi alterado")
+	} else {
+		fmt.Printf("📊 %d manifest(s) migrado(s) para %s\n", migrated, to)
+		if skipped > 0 {
+			fmt.Printf("⏸️  %d manifest(s) não gravado(s) por pendências manuais\n", skipped)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d manifest(s) com erro", failed)
+	}
+	if manual > 0 && opts.Force && !opts.DryRun {
+		fmt.Printf("⚠️  --force: %d item(ns) marcados com ⚠️ foram gravados sem revisão; o migrate não os listará de novo\n", manual)
+		return nil
+	}
+	if manual > 0 {
+		return fmt.Errorf("%d item(ns) exigem revisão manual (marcados com ⚠️)", manual)
+	}
+	return nil
+}
+
+// RunMigrateWithExit executa RunMigrate e faz os.Exit apropriado em caso de erro
+func RunMigrateWithExit(manifestPaths []string, opts MigrateOptions) {
+	if err := RunMigrate(manifestPaths, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
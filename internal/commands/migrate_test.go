@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyManifest = `application:
+  code: sagep-biopass
+  name: Biopass
+permissions:
+  - code: biopass.devices.read
+  # Sem ação CASL.js válida: exige revisão manual
+  - code: biopass.devices.export
+roles:
+  - code: biopass.viewer
+    name: Visualizador
+    permissions: [biopass.devices.read]
+`
+
+func TestRunMigrateKeepsFileWithPendingItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth-manifest.yaml")
+	if err := os.WriteFile(path, []byte(legacyManifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunMigrate([]string{path}, MigrateOptions{}); err == nil {
+		t.Fatal("itens ⚠️ pendentes devem fazer o migrate sair com erro")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyManifest {
+		t.Fatalf("o arquivo não pode ser gravado com pendências:\n%s", data)
+	}
+
+	// Sem gravar, a próxima execução continua apontando o item
+	if err := RunMigrate([]string{path}, MigrateOptions{}); err == nil {
+		t.Fatal("a pendência deve continuar sendo reportada")
+	}
+
+	if err := RunMigrate([]string{path}, MigrateOptions{Force: true}); err != nil {
+		t.Fatalf("--force deve gravar mesmo com pendências: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "apiVersion:") || !strings.Contains(string(data), "subject: biopass.devices") {
+		t.Fatalf("--force deve gravar a migração:\n%s", data)
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/fakeserver"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// MockServerOptions contém as opções do comando mock-server
+type MockServerOptions struct {
+	Addr     string        // Endereço de escuta (ex: :8080)
+	Secret   string        // BOOTSTRAP_SECRET aceito nas assinaturas HMAC
+	Keys     []string      // Secrets adicionais por X-Key-Id, no formato id=secret
+	Seeds    []string      // Manifests (arquivos, globs ou diretórios) sincronizados na inicialização
+	TokenTTL time.Duration // Validade dos tokens de /v1/authenticate
+}
+
+// RunMockServer sobe um sagep-auth em memória para desenvolvimento local
+// Os dados são perdidos ao encerrar o processo
+func RunMockServer(opts MockServerOptions) error {
+	keySecrets := make(map[string]string, len(opts.Keys))
+	for _, key := range opts.Keys {
+		id, secret, ok := strings.Cut(key, "=")
+		if !ok || id == "" || secret == "" {
+			return fmt.Errorf("--key inválido %q (formato: id=secret)", key)
+		}
+		keySecrets[id] = secret
+	}
+	if opts.Secret == "" && len(keySecrets) == 0 {
+		return fmt.Errorf("informe --secret (ou SAGEP_AUTH_SECRET / BOOTSTRAP_SECRET) ou --key para aceitar HMAC")
+	}
+
+	server := fakeserver.New(fakeserver.Options{
+		Secret:     opts.Secret,
+		KeySecrets: keySecrets,
+		TokenTTL:   opts.TokenTTL,
+		Logger:     log.New(os.Stderr, "mock-server ", log.LstdFlags),
+	})
+
+	if len(opts.Seeds) > 0 {
+		paths, err := manifest.ResolvePaths(opts.Seeds)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			m, err := manifest.LoadManifest(path)
+			if err != nil {
+				return fmt.Errorf("erro ao carregar %s: %w", path, err)
+			}
+			resp, err := server.Apply(m)
+			if err != nil {
+				return fmt.Errorf("erro ao carregar %s: %w", path, err)
+			}
+			problems := analyzeSyncResponse(m, resp, nil)
+			fmt.Printf("✅ %s carregado (%d permissions, %d roles, %d users)\n", m.Application.Code, len(resp.Permissions), len(resp.Roles), len(resp.Users))
+			for _, p := range problems {
+				fmt.Printf("  ⚠️  %s %s: %s\n", p.Section, p.Code, p.Message)
+			}
+		}
+	}
+
+	fmt.Printf("🚀 sagep-auth fake em http://%s\n", displayAddr(opts.Addr))
+	fmt.Println("   POST /v1/applications/sync, GET /v1/applications/{code}, POST /v1/authenticate, GET /me")
+	if len(keySecrets) > 0 {
+		ids := make([]string, 0, len(keySecrets))
+		for id := range keySecrets {
+			ids = append(ids, id)
+		}
+		fmt.Printf("   X-Key-Id aceitos: %s\n", strings.Join(ids, ", "))
+	}
+	fmt.Println("   ⚠️  Apenas para desenvolvimento: dados em memória, senhas em texto claro")
+
+	return http.ListenAndServe(opts.Addr, server.Handler())
+}
+
+// displayAddr completa o host para exibição (":8080" → "localhost:8080")
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+// RunMockServerWithExit executa RunMockServer e faz os.Exit apropriado em caso de erro
+func RunMockServerWithExit(opts MockServerOptions) {
+	if err := RunMockServer(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+)
+
+// RunProfile executa os subcomandos de perfis (list, use, show)
+// profileFlag é o --profile global, usado por show quando nenhum nome é informado
+func RunProfile(args []string, profileFlag string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("subcomando não especificado (use: profile list|use <nome>|show [nome])")
+	}
+
+	switch args[0] {
+	case "list":
+		return runProfileList(profileFlag)
+	case "use":
+		if len(args) != 2 {
+			return fmt.Errorf("uso: profile use <nome>")
+		}
+		return runProfileUse(args[1])
+	case "show":
+		if len(args) > 2 {
+			return fmt.Errorf("uso: profile show [nome]")
+		}
+		name := profileFlag
+		if len(args) == 2 {
+			name = args[1]
+		}
+		return runProfileShow(name)
+	default:
+		return fmt.Errorf("subcomando desconhecido '%s' (use: list, use, show)", args[0])
+	}
+}
+
+// runProfileList lista os perfis, marcando o ativo com *
+func runProfileList(profileFlag string) error {
+	pf, path, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if len(pf.Profiles) == 0 {
+		fmt.Printf("Nenhum perfil configurado em %s\n", path)
+		return nil
+	}
+
+	active, _, _, err := config.ResolveProfile(profileFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Perfis em %s\n\n", path)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ATIVO\tNOME\tURL\tPROTEGIDO\tDESCRIÇÃO")
+	for _, name := range pf.Names() {
+		p := pf.Profiles[name]
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+		protected := "não"
+		if p.Protected {
+			protected = "sim"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, name, p.URL, protected, p.Description)
+	}
+	return w.Flush()
+}
+
+// runProfileUse define o perfil atual (current_profile) no config.yaml
+func runProfileUse(name string) error {
+	pf, path, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := pf.Profiles[name]; !ok {
+		if len(pf.Profiles) == 0 {
+			return fmt.Errorf("perfil %q não encontrado: %s não existe ou não tem profiles", name, path)
+		}
+		return fmt.Errorf("perfil %q não encontrado em %s (disponíveis: %s)", name, path, strings.Join(pf.Names(), ", "))
+	}
+
+	if err := config.SetCurrentProfile(path, name); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Perfil atual: %s (%s)\n", name, pf.Profiles[name].URL)
+	if pf.Profiles[name].Protected {
+		fmt.Println("⚠️  Perfil protegido: comandos que alteram o servidor pedirão confirmação")
+	}
+	if env := os.Getenv("SAGEP_AUTH_PROFILE"); env != "" && env != name {
+		fmt.Printf("⚠️  SAGEP_AUTH_PROFILE=%s está definido e tem precedência sobre current_profile\n", env)
+	}
+	return nil
+}
+
+// runProfileShow exibe o perfil (secrets e tokens mascarados)
+func runProfileShow(profileFlag string) error {
+	name, p, source, err := config.ResolveProfile(profileFlag)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		path, _ := config.ProfilesPath()
+		fmt.Printf("Nenhum perfil ativo em %s - usando .env / variáveis de ambiente\n", path)
+		return nil
+	}
+
+	fmt.Printf("Perfil:      %s (selecionado por %s)\n", name, source)
+	fmt.Printf("URL:         %s\n", p.URL)
+	if p.Description != "" {
+		fmt.Printf("Descrição:   %s\n", p.Description)
+	}
+	fmt.Printf("Protegido:   %t\n", p.Protected)
+	fmt.Printf("Secret:      %s\n", maskSecret(p.Secret, p.SecretEnv, "--secret"))
+	fmt.Printf("Token:       %s\n", maskSecret(p.Token, p.TokenEnv, "--token ou login"))
+	if p.SignatureVersion != "" {
+		fmt.Printf("Assinatura:  %s\n", p.SignatureVersion)
+	}
+	if p.KeyID != "" {
+		fmt.Printf("Key ID:      %s\n", p.KeyID)
+	}
+
+	creds, err := config.LoadCredentials(name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case creds == nil:
+		fmt.Println("Login:       (nenhum)")
+	case creds.Expired():
+		fmt.Printf("Login:       %s (expirado em %s)\n", creds.Email, creds.ExpiresAt.Local().Format("02/01/2006 15:04"))
+	default:
+		fmt.Printf("Login:       %s\n", creds.Email)
+	}
+	return nil
+}
+
+// maskSecret indica de onde vem um secret do perfil sem exibi-lo
+// Com perfil ativo não há fallback para .env / SAGEP_AUTH_*: só o perfil, a variável *_env ou o flag
+func maskSecret(value, envName, fallback string) string {
+	switch {
+	case value != "":
+		return "******** (definido no perfil)"
+	case envName == "":
+		return fmt.Sprintf("(não definido; use %s)", fallback)
+	case os.Getenv(envName) != "":
+		return fmt.Sprintf("******** (variável %s)", envName)
+	default:
+		return fmt.Sprintf("(variável %s não definida)", envName)
+	}
+}
+
+// confirmProtectedProfile exige confirmação antes de alterar um servidor de perfil protegido
+// Em terminal interativo, o nome do perfil deve ser digitado; fora dele, é necessário --yes
+func confirmProtectedProfile(cfg *config.Config, yes bool) error {
+	if !cfg.Protected {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "🚨🚨🚨 PERFIL PROTEGIDO 🚨🚨🚨")
+	fmt.Fprintf(os.Stderr, "   Perfil: %s\n", cfg.Profile)
+	fmt.Fprintf(os.Stderr, "   URL:    %s\n", cfg.AuthURL)
+	fmt.Fprintln(os.Stderr, "   Este comando vai ALTERAR dados neste servidor.")
+	fmt.Fprintln(os.Stderr, "")
+
+	if yes {
+		fmt.Fprintln(os.Stderr, "   Confirmado via --yes")
+		return nil
+	}
+	if !isInteractive() {
+		return fmt.Errorf("perfil %q é protegido: use --yes para confirmar fora de um terminal interativo", cfg.Profile)
+	}
+
+	var typed string
+	if err := survey.AskOne(&survey.Input{
+		Message: fmt.Sprintf("Digite o nome do perfil (%s) para continuar:", cfg.Profile),
+	}, &typed, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+		return fmt.Errorf("erro ao ler confirmação: %w", err)
+	}
+	if strings.TrimSpace(typed) != cfg.Profile {
+		return fmt.Errorf("confirmação não corresponde ao perfil %q - operação cancelada", cfg.Profile)
+	}
+	return nil
+}
+
+// RunProfileWithExit executa RunProfile e faz os.Exit apropriado em caso de erro
+func RunProfileWithExit(args []string, profileFlag string) {
+	if err := RunProfile(args, profileFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/report"
+)
+
+// writeReports grava os relatórios pedidos via --report (JUnit .xml ou Markdown .md)
+func writeReports(progress io.Writer, paths []string, r *report.Report) error {
+	for _, path := range paths {
+		if err := report.WriteFile(path, r); err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "📄 Relatório gerado: %s\n", path)
+	}
+	return nil
+}
+
+// checkReportPaths valida as extensões dos relatórios antes de executar o comando
+func checkReportPaths(paths []string) error {
+	for _, path := range paths {
+		if err := report.CheckPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSyncReport converte os resultados do sync em relatório
+// Cada permission, role e usuário do manifest é um item; o status vem da resposta do servidor:
+// erro → falha; ignorado ou ausente na resposta → ignorado (falha com strict)
+func buildSyncReport(targets []*syncTarget, sections []string, strict bool) *report.Report {
+	r := &report.Report{Command: "sync", GeneratedAt: time.Now()}
+
+	for _, t := range targets {
+		m := t.Manifest
+		suite := report.Suite{Name: m.Application.Code, File: t.Path, Duration: t.Duration}
+
+		if t.Err != nil {
+			suite.Cases = append(suite.Cases, report.Case{Section: "application", Name: m.Application.Code, Status: report.StatusFailed, Message: t.Err.Error()})
+			for _, c := range manifestCases(m, sections) {
+				c.Status = report.StatusSkipped
+				c.Message = "sync não concluído"
+				suite.Cases = append(suite.Cases, c)
+			}
+			r.Suites = append(r.Suites, suite)
+			continue
+		}
+
+		resp := t.Response
+		actions := make(map[string]map[string]string) // seção → code → action
+		actions["application"] = map[string]string{resp.Application.Code: resp.Application.Action}
+		actions[manifest.SectionPermissions] = make(map[string]string)
+		for _, p := range resp.Permissions {
+			actions[manifest.SectionPermissions][p.Code] = p.Action
+		}
+		actions[manifest.SectionRoles] = make(map[string]string)
+		for _, role := range resp.Roles {
+			actions[manifest.SectionRoles][role.Code] = role.Action
+		}
+		actions[manifest.SectionUsers] = make(map[string]string)
+		for _, u := range resp.Users {
+			actions[manifest.SectionUsers][strings.ToLower(u.Code)] = u.Action
+		}
+
+		actions[sectionRemovedUsers] = make(map[string]string)
+		for _, u := range resp.RemovedUsers {
+			actions[sectionRemovedUsers][strings.ToLower(u.Code)] = u.Action
+		}
+
+		// Problemas em role → permission entram no caso da role: sem isso um erro
+		// aninhado não apareceria no relatório
+		problems := make(map[string][]syncItemProblem)
+		for _, p := range t.Problems {
+			code := p.Code
+			if parent, _, nested := strings.Cut(code, " → "); nested {
+				code = parent
+			}
+			key := p.Section + "/" + code
+			if p.Section == manifest.SectionUsers || p.Section == sectionRemovedUsers {
+				key = strings.ToLower(key)
+			}
+			problems[key] = append(problems[key], p)
+		}
+
+		caseFor := func(c report.Case) report.Case {
+			key := c.Section + "/" + c.Name
+			action := actions[c.Section][c.Name]
+			if c.Section == manifest.SectionUsers || c.Section == sectionRemovedUsers {
+				key = strings.ToLower(key)
+				action = actions[c.Section][strings.ToLower(c.Name)]
+			}
+			c.Status = report.StatusPassed
+			if list := problems[key]; len(list) > 0 {
+				c.Status = report.StatusSkipped
+				var messages []string
+				for _, p := range list {
+					if p.Status == itemError || strict {
+						c.Status = report.StatusFailed
+					}
+					message := p.Message
+					if message == "" {
+						message = p.Status
+					}
+					if !strings.EqualFold(p.Code, c.Name) {
+						message = p.Code + ": " + message // role → permission
+					}
+					messages = append(messages, message)
+				}
+				c.Message = strings.Join(messages, "; ")
+				return c
+			}
+			c.Action = action
+			return c
+		}
+
+		suite.Cases = append(suite.Cases, caseFor(report.Case{Section: "application", Name: resp.Application.Code}))
+		for _, c := range manifestCases(m, sections) {
+			suite.Cases = append(suite.Cases, caseFor(c))
+		}
+		for _, removal := range t.Removals {
+			suite.Cases = append(suite.Cases, caseFor(report.Case{Section: sectionRemovedUsers, Name: removal.Email}))
+		}
+		r.Suites = append(r.Suites, suite)
+	}
+
+	return r
+}
+
+// manifestCases lista os itens do manifest (das seções enviadas) como casos do relatório
+func manifestCases(m *manifest.AuthManifest, sections []string) []report.Case {
+	var cases []report.Case
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		for _, p := range m.Permissions {
+			cases = append(cases, report.Case{Section: manifest.SectionPermissions, Name: p.Code})
+		}
+	}
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		for _, role := range m.Roles {
+			cases = append(cases, report.Case{Section: manifest.SectionRoles, Name: role.Code})
+		}
+	}
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		for _, u := range m.Users {
+			cases = append(cases, report.Case{Section: manifest.SectionUsers, Name: u.Email})
+		}
+	}
+	return cases
+}
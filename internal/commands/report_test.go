@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/report"
+)
+
+func TestBuildSyncReportNestedRolePermissionError(t *testing.T) {
+	m := &manifest.AuthManifest{
+		Application: manifest.Application{Code: "sagep-biopass", Name: "Biopass"},
+		Permissions: []manifest.Permission{{Code: "biopass.devices.read", Subject: "biopass.devices", Action: "read"}},
+		Roles:       []manifest.Role{{Code: "biopass.viewer", Name: "Viewer", Permissions: []string{"biopass.devices.read"}}},
+	}
+	resp := &client.SyncResponse{
+		Application: client.SyncResultDTO{Code: "sagep-biopass", Action: client.ActionUpdated},
+		Permissions: []client.SyncResultDTO{{Code: "biopass.devices.read", Action: client.ActionUpdated}},
+		Roles: []client.SyncRoleResultDTO{{
+			Code:        "biopass.viewer",
+			Action:      client.ActionUpdated,
+			Permissions: []client.SyncResultDTO{{Code: "biopass.devices.read", Action: client.ActionError, Message: "falha ao vincular"}},
+		}},
+	}
+	sections := manifest.AllSections
+	target := &syncTarget{Manifest: m, Response: resp, Problems: analyzeSyncResponse(m, resp, sections)}
+
+	r := buildSyncReport([]*syncTarget{target}, sections, false)
+
+	_, failed, _ := r.Counts()
+	if failed != 1 {
+		t.Fatalf("esperava 1 falha no relatório, obteve %d", failed)
+	}
+	for _, c := range r.Suites[0].Cases {
+		if c.Section == manifest.SectionRoles {
+			if c.Status != report.StatusFailed || !strings.Contains(c.Message, "falha ao vincular") {
+				t.Errorf("caso da role deveria falhar com o erro aninhado: %+v", c)
+			}
+		}
+	}
+}
+
+func TestBuildSyncReportRemovedUsers(t *testing.T) {
+	m := &manifest.AuthManifest{Application: manifest.Application{Code: "app", Name: "App"}}
+	resp := &client.SyncResponse{Application: client.SyncResultDTO{Code: "app", Action: client.ActionUpdated}}
+	removals := []client.RemovedUser{{Email: "ex@sagep.com.br", Action: client.RemoveDeactivate}}
+	target := &syncTarget{Manifest: m, Response: resp, Removals: removals, Problems: analyzeRemovals(removals, resp)}
+
+	r := buildSyncReport([]*syncTarget{target}, []string{}, false)
+
+	if _, failed, _ := r.Counts(); failed != 1 {
+		t.Fatalf("remoção não confirmada deveria falhar no relatório: %+v", r.Suites[0].Cases)
+	}
+}
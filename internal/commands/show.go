@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// ShowOptions contém as opções do comando show
+type ShowOptions struct {
+	Payload  bool     // Exibe o payload final do sync em vez do manifest
+	Sections []string // Seções exibidas (--only/--skip); nil = todas
+	Hash     bool     // Troca os valores sensíveis por um hash curto em vez de ***
+	Output   string   // yaml ou json (padrão: yaml para o manifest, json para o payload)
+}
+
+// RunShow exibe o manifest efetivo (ou o payload do sync) com os segredos mascarados
+// Sem --hash não precisa das chaves: valores ENC[...] são mascarados sem decifrar
+func RunShow(manifestPath string, opts ShowOptions) error {
+	output := opts.Output
+	if output == "" {
+		output = OutputYAML
+		if opts.Payload {
+			output = OutputJSON
+		}
+	}
+	if output != OutputYAML && output != OutputJSON {
+		return fmt.Errorf("formato de saída inválido %q (válidos: json, yaml)", output)
+	}
+
+	doc, err := manifest.LoadDocument(manifestPath)
+	if err != nil {
+		return err
+	}
+	// O hash precisa do texto claro: sem ele, senhas iguais teriam hashes diferentes
+	if opts.Hash && doc.HasEncrypted() {
+		identities, err := secrets.LoadIdentities(nil)
+		if err != nil {
+			return err
+		}
+		if _, err := doc.Decrypt(identities); err != nil {
+			return fmt.Errorf("erro ao decifrar manifest: %w", err)
+		}
+	}
+	doc.Redact(opts.Hash)
+
+	m, err := doc.Manifest()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if opts.Payload {
+		payload, err := client.BuildSyncPayload(m, opts.Sections)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar payload: %w", err)
+		}
+		data, err = formatPayload(payload, output)
+		if err != nil {
+			return err
+		}
+	} else {
+		data, err = formatManifest(filterSections(m, opts.Sections), output)
+		if err != nil {
+			return err
+		}
+	}
+	os.Stdout.Write(data)
+	return nil
+}
+
+// filterSections remove do manifest as seções não selecionadas
+func filterSections(m *manifest.AuthManifest, sections []string) *manifest.AuthManifest {
+	if sections == nil {
+		return m
+	}
+	filtered := *m
+	if !manifest.HasSection(sections, manifest.SectionPermissions) {
+		filtered.Permissions = nil
+	}
+	if !manifest.HasSection(sections, manifest.SectionRoles) {
+		filtered.Roles = nil
+	}
+	if !manifest.HasSection(sections, manifest.SectionUsers) {
+		filtered.Users = nil
+	}
+	return &filtered
+}
+
+// formatManifest serializa o manifest no formato escolhido
+func formatManifest(m *manifest.AuthManifest, output string) ([]byte, error) {
+	if output == OutputJSON {
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatPayload formata o payload JSON do sync (indentado, ou convertido para yaml)
+// A ordem dos campos é a mesma enviada ao servidor
+func formatPayload(payload []byte, output string) ([]byte, error) {
+	if output == OutputJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, payload, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(payload, &node); err != nil {
+		return nil, err
+	}
+	// JSON é lido como YAML em estilo flow; exibir em estilo bloco
+	setBlockStyle(&node)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func setBlockStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
+
+// RunShowWithExit executa RunShow e faz os.Exit apropriado em caso de erro
+func RunShowWithExit(manifestPath string, opts ShowOptions) {
+	if err := RunShow(manifestPath, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/lockfile"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Situação de um ambiente em relação ao manifest local
+const (
+	statusUpToDate = "✅ em dia"
+	statusBehind   = "⚠️  desatualizado"
+	statusNever    = "⏳ nunca sincronizado"
+)
+
+// lockChange é um item do manifest que o próximo sync vai criar, alterar ou que saiu do manifest
+type lockChange struct {
+	Kind    string // "+" novo, "~" alterado, "-" fora do manifest (o sync não remove)
+	Section string
+	Code    string
+	ID      string
+}
+
+// RunStatus mostra, sem contatar o servidor, quais ambientes estão atrás do manifest local
+// Os ambientes são os registrados no auth-manifest.lock e os perfis do config.yaml;
+// para os desatualizados, lista os itens (e IDs) que o próximo sync vai tocar
+func RunStatus(manifestPaths []string) error {
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+
+	// Perfis configurados entram mesmo que nunca tenham sido sincronizados
+	profileEnvs := map[string]bool{}
+	if pf, _, err := config.LoadProfiles(); err == nil {
+		for name, p := range pf.Profiles {
+			profileEnvs[lockfile.EnvironmentKey(name, p.URL)] = true
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+
+	behind := 0
+	for i, path := range paths {
+		m, err := manifest.LoadManifestWithoutKeys(path)
+		if err != nil {
+			return fmt.Errorf("erro ao carregar %s: %w", path, err)
+		}
+		lockPath := lockfile.Path(path)
+		l, err := lockfile.Load(lockPath)
+		if err != nil {
+			return err
+		}
+
+		envs := make([]string, 0, len(l.Environments)+len(profileEnvs))
+		for env := range profileEnvs {
+			envs = append(envs, env)
+		}
+		for env := range l.Environments {
+			if !profileEnvs[env] {
+				envs = append(envs, env)
+			}
+		}
+		sort.Strings(envs)
+
+		if i > 0 {
+			fmt.Println()
+		}
+		hash := m.Hash()
+		fmt.Printf("%s (%s)  manifest %s\n", m.Application.Code, path, hash[:12])
+		if len(envs) == 0 {
+			fmt.Printf("   Nenhum ambiente: sem %s e sem perfis configurados\n", lockPath)
+			continue
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "   AMBIENTE\tSTATUS\tÚLTIMO SYNC\tMANIFEST\tCLI")
+		changes := map[string][]lockChange{}
+		for _, env := range envs {
+			last := l.Get(env, m.Application.Code)
+			switch {
+			case last == nil:
+				fmt.Fprintf(w, "   %s\t%s\t-\t-\t-\n", env, statusNever)
+				behind++
+				continue
+			case last.UpToDate(hash, nil):
+				fmt.Fprintf(w, "   %s\t%s", env, statusUpToDate)
+			default:
+				fmt.Fprintf(w, "   %s\t%s", env, statusBehind)
+				changes[env] = lockChanges(m, last)
+				behind++
+			}
+			fmt.Fprintf(w, "\t%s\t%s\t%s\n", last.SyncedAt.Local().Format("02/01/2006 15:04"), shortHash(last.ManifestHash), last.CLIVersion)
+		}
+		w.Flush()
+
+		for _, env := range envs {
+			if list, ok := changes[env]; ok {
+				printLockChanges(env, list)
+			}
+		}
+	}
+
+	if behind > 0 {
+		fmt.Printf("\n%d ambiente(s) atrás do manifest local: rode 'sync' com o perfil correspondente\n", behind)
+	}
+	return nil
+}
+
+// lockChanges compara o manifest local com o último sync registrado no lock
+func lockChanges(m *manifest.AuthManifest, last *lockfile.Application) []lockChange {
+	var changes []lockChange
+	diff := func(section string, current map[string]string, synced map[string]lockfile.Item) {
+		codes := make([]string, 0, len(current))
+		for code := range current {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			item, ok := synced[code]
+			switch {
+			case !ok:
+				changes = append(changes, lockChange{Kind: "+", Section: section, Code: code})
+			case item.Hash != current[code]:
+				changes = append(changes, lockChange{Kind: "~", Section: section, Code: code, ID: item.ID})
+			}
+		}
+		for _, code := range lockfile.SortedKeys(synced) {
+			if _, ok := current[code]; !ok {
+				changes = append(changes, lockChange{Kind: "-", Section: section, Code: code, ID: synced[code].ID})
+			}
+		}
+	}
+	diff("permission", lockfile.PermissionHashes(m), last.Permissions)
+	diff("role", lockfile.RoleHashes(m), last.Roles)
+	diff("user", lockfile.UserHashes(m), last.Users)
+	return changes
+}
+
+// printLockChanges lista os itens que o próximo sync vai tocar em um ambiente
+func printLockChanges(env string, changes []lockChange) {
+	fmt.Printf("\n   %s:\n", env)
+	if len(changes) == 0 {
+		fmt.Println("     (manifest alterado sem mudança nos itens: application ou seções não sincronizadas)")
+		return
+	}
+	for _, c := range changes {
+		switch c.Kind {
+		case "+":
+			fmt.Printf("     + %s %s (novo)\n", c.Section, c.Code)
+		case "~":
+			fmt.Printf("     ~ %s %s (id %s)\n", c.Section, c.Code, c.ID)
+		default:
+			fmt.Printf("     - %s %s (id %s; fora do manifest, o sync não remove)\n", c.Section, c.Code, c.ID)
+		}
+	}
+}
+
+// shortHash abrevia um hash para exibição
+func shortHash(hash string) string {
+	hash = strings.TrimPrefix(hash, "sha256:")
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// RunStatusWithExit executa RunStatus e faz os.Exit apropriado em caso de erro
+func RunStatusWithExit(manifestPaths []string) {
+	if err := RunStatus(manifestPaths); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,85 +1,307 @@
 package commands
 
 import (
-	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/lockfile"
 	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
 )
 
-// RunSync executa o comando de sincronização
-func RunSync(manifestPath string, cfg *config.Config) error {
-	// Carregar manifest
-	m, err := manifest.LoadManifest(manifestPath)
-	if err != nil {
-		return fmt.Errorf("erro ao carregar manifest: %w", err)
-	}
+// SyncOptions contém as opções do comando sync
+type SyncOptions struct {
+	Concurrency int      // Número máximo de manifests sincronizados em paralelo (batch)
+	Sections    []string // Seções a sincronizar (--only/--skip); nil = todas
+	Output      string   // Formato da saída: table (padrão), json ou yaml
+	Reports     []string // Relatórios a gerar (--report junit.xml, --report summary.md)
+	Strict      bool     // Falha também quando itens são ignorados ou ausentes na resposta
+	Yes         bool     // Confirma o sync em perfil protegido sem perguntar
+	Force       bool     // Sincroniza mesmo se o auth-manifest.lock indicar que o ambiente está em dia
+	Bundle      string   // Grava o payload em um bundle offline em vez de enviar (--bundle)
+	BundleKey   string   // Chave HMAC para assinar o bundle (opcional)
+	BundleKeyID string   // Identificador da chave do bundle (opcional)
+	TenantsPath string   // Registro de tenants (padrão: tenants.yaml ao lado do manifest)
 
-	// Criar cliente
-	authClient := client.NewAuthClient(cfg.AuthURL, cfg.AuthToken, cfg.AuthSecret)
+	Users        string // merge (padrão) ou authoritative: o manifest é a lista completa de usuários
+	RemovedUsers string // Com authoritative: deactivate (padrão) ou unlink
+}
 
-	// Exibir informações iniciais
-	fmt.Printf("Sincronizando aplicação: %s\n", m.Application.Code)
-	fmt.Printf("URL do auth: %s\n\n", cfg.AuthURL)
+// syncStats contém as contagens de itens criados/atualizados de um SyncResponse
+type syncStats struct {
+	PermsCreated int `json:"permissions_created" yaml:"permissions_created"`
+	PermsUpdated int `json:"permissions_updated" yaml:"permissions_updated"`
+	RolesCreated int `json:"roles_created" yaml:"roles_created"`
+	RolesUpdated int `json:"roles_updated" yaml:"roles_updated"`
+	UsersCreated int `json:"users_created" yaml:"users_created"`
+	UsersUpdated int `json:"users_updated" yaml:"users_updated"`
 
-	// Executar sync
-	ctx := context.Background()
-	resp, err := authClient.SyncApplication(ctx, m)
-	if err != nil {
-		return fmt.Errorf("erro ao sincronizar: %w", err)
-	}
+	UsersDeactivated int `json:"users_deactivated,omitempty" yaml:"users_deactivated,omitempty"`
+	UsersUnlinked    int `json:"users_unlinked,omitempty" yaml:"users_unlinked,omitempty"`
+}
 
-	// Calcular estatísticas
-	permsCreated := 0
-	permsUpdated := 0
+// add soma as contagens de outro syncStats
+func (s *syncStats) add(other syncStats) {
+	s.PermsCreated += other.PermsCreated
+	s.PermsUpdated += other.PermsUpdated
+	s.RolesCreated += other.RolesCreated
+	s.RolesUpdated += other.RolesUpdated
+	s.UsersCreated += other.UsersCreated
+	s.UsersUpdated += other.UsersUpdated
+	s.UsersDeactivated += other.UsersDeactivated
+	s.UsersUnlinked += other.UsersUnlinked
+}
+
+// computeSyncStats calcula as estatísticas de um SyncResponse
+func computeSyncStats(resp *client.SyncResponse) syncStats {
+	var s syncStats
 	for _, perm := range resp.Permissions {
 		if perm.Action == "created" {
-			permsCreated++
+			s.PermsCreated++
 		} else if perm.Action == "updated" {
-			permsUpdated++
+			s.PermsUpdated++
 		}
 	}
 
-	rolesCreated := 0
-	rolesUpdated := 0
 	for _, role := range resp.Roles {
 		if role.Action == "created" {
-			rolesCreated++
+			s.RolesCreated++
 		} else if role.Action == "updated" {
-			rolesUpdated++
+			s.RolesUpdated++
 		}
 	}
 
-	usersCreated := 0
-	usersUpdated := 0
 	for _, user := range resp.Users {
 		if user.Action == "created" {
-			usersCreated++
+			s.UsersCreated++
 		} else if user.Action == "updated" {
-			usersUpdated++
+			s.UsersUpdated++
+		}
+	}
+
+	for _, user := range resp.RemovedUsers {
+		switch user.Action {
+		case client.ActionDeactivated:
+			s.UsersDeactivated++
+		case client.ActionUnlinked:
+			s.UsersUnlinked++
+		}
+	}
+	return s
+}
+
+// RunSync executa o comando de sincronização
+// manifestPaths aceita arquivos, globs (ex: "apps/*.yaml") e diretórios;
+// com mais de um manifest, os syncs rodam em paralelo e o resumo é agregado
+// Com Output json/yaml, o resultado vai para stdout e o progresso para stderr
+func RunSync(manifestPaths []string, cfg *config.Config, opts SyncOptions) error {
+	output, err := parseOutputFormat(opts.Output)
+	if err != nil {
+		return err
+	}
+	if err := checkReportPaths(opts.Reports); err != nil {
+		return err
+	}
+	if err := validateUsersMode(opts); err != nil {
+		return err
+	}
+	authoritative := opts.Users == UsersAuthoritative
+	progress := io.Writer(os.Stdout)
+	if output != OutputTable {
+		progress = os.Stderr
+	}
+
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+	batch := len(paths) > 1
+
+	// Carregar e validar todos os manifests antes de qualquer envio
+	targets, err := loadSyncTargets(paths, opts.Sections)
+	if err != nil {
+		return err
+	}
+	if err := checkPasswordPolicy(targets, opts.Sections); err != nil {
+		return err
+	}
+	if err := checkTenants(targets, opts.Sections, opts.TenantsPath); err != nil {
+		return err
+	}
+
+	// Exibir informações iniciais
+	if batch {
+		fmt.Fprintf(progress, "Sincronizando %d aplicações (%d em paralelo)\n", len(targets), effectiveConcurrency(opts.Concurrency, len(targets)))
+	} else {
+		fmt.Fprintf(progress, "Sincronizando aplicação: %s\n", targets[0].Manifest.Application.Code)
+	}
+	fmt.Fprintf(progress, "URL do auth: %s\n", cfg.AuthURL)
+	if cfg.Profile != config.DefaultProfile {
+		fmt.Fprintf(progress, "Perfil: %s\n", cfg.Profile)
+	}
+	if opts.Sections != nil {
+		fmt.Fprintf(progress, "Seções: %s\n", strings.Join(opts.Sections, ", "))
+	}
+	for _, t := range targets {
+		for _, warning := range t.Warnings {
+			if batch {
+				fmt.Fprintf(progress, "⚠️  %s: %s\n", t.Manifest.Application.Code, warning)
+			} else {
+				fmt.Fprintf(progress, "⚠️  %s\n", warning)
+			}
+		}
+	}
+
+	// auth-manifest.lock: pular manifests já sincronizados neste ambiente
+	env := lockfile.EnvironmentKey(cfg.Profile, cfg.AuthURL)
+	locks, err := loadSyncLocks(targets)
+	if err != nil {
+		return err
+	}
+	passwords, passwordsPath := loadPasswordState(progress)
+	var skipped []string
+	// Com --users=authoritative, o servidor pode ter usuários novos mesmo com o manifest em dia
+	if !opts.Force && !authoritative {
+		targets, skipped = skipUpToDate(progress, env, locks, passwords, targets, opts.Sections)
+	}
+	if len(targets) == 0 {
+		if output != OutputTable {
+			out := buildSyncOutput(cfg, opts, nil, time.Now(), 0)
+			out.Skipped = skipped
+			if err := writeSyncOutput(os.Stdout, output, out); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(progress, "\nNada a sincronizar: o ambiente já está em dia (use --force para sincronizar mesmo assim).")
+		return nil
+	}
+
+	// Token salvo pelo login (se não houver --token/SAGEP_AUTH_TOKEN)
+	if err := applySavedLogin(cfg, progress); err != nil {
+		return err
+	}
+	fmt.Fprintln(progress)
+
+	// Perfil protegido: confirmar antes de qualquer envio
+	if err := confirmProtectedProfile(cfg, opts.Yes); err != nil {
+		return err
+	}
+
+	authClient, err := newAuthClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	// --users=authoritative: prévia e confirmação dos usuários a desativar/desvincular
+	if authoritative {
+		if err := planUserRemovals(authClient, targets, opts.RemovedUsers); err != nil {
+			return err
+		}
+		if total := printRemovalPlan(progress, targets, opts.RemovedUsers); total > 0 {
+			if err := confirmUserRemovals(total, opts.RemovedUsers, opts.Yes); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(progress)
+	}
+
+	// Executar sync
+	startedAt := time.Now()
+	runSyncTargets(authClient, targets, opts)
+	duration := time.Since(startedAt)
+
+	failed := 0
+	for _, t := range targets {
+		if t.Err == nil {
+			t.Problems = analyzeSyncResponse(t.Manifest, t.Response, opts.Sections)
+			t.Problems = append(t.Problems, analyzeRemovals(t.Removals, t.Response)...)
+		}
+		if t.failed(opts.Strict) {
+			failed++
+		}
+	}
+
+	// Exibir resultado
+	switch {
+	case output != OutputTable:
+		out := buildSyncOutput(cfg, opts, targets, startedAt, duration)
+		out.Skipped = skipped
+		if err := writeSyncOutput(os.Stdout, output, out); err != nil {
+			return err
 		}
+	case batch:
+		printBatchReport(targets, opts.Strict)
+	case targets[0].Err == nil:
+		printSyncSummary(targets[0].Response, opts.Sections, targets[0].Problems)
+	}
+
+	if err := writeReports(progress, opts.Reports, buildSyncReport(targets, opts.Sections, opts.Strict)); err != nil {
+		return err
+	}
+
+	recordSyncLocks(progress, cfg, env, locks, passwords, passwordsPath, targets, opts.Sections)
+
+	if failed > 0 {
+		if !batch {
+			if targets[0].Err == nil {
+				return fmt.Errorf("sync concluído com %d item(ns) não sincronizado(s)", len(targets[0].Problems))
+			}
+			return fmt.Errorf("erro ao sincronizar: %w", targets[0].Err)
+		}
+		return fmt.Errorf("%d de %d aplicação(ões) falharam no sync", failed, len(targets))
+	}
+
+	fmt.Fprintln(progress, "\nSync concluído com sucesso.")
+	return nil
+}
+
+// printSyncSummary exibe o resumo do sync de uma única aplicação
+func printSyncSummary(resp *client.SyncResponse, sections []string, problems []syncItemProblem) {
+	// Calcular estatísticas
+	stats := computeSyncStats(resp)
+	pending := make(map[string]int)
+	for _, p := range problems {
+		pending[p.Section]++
 	}
 
 	// Exibir resumo
 	fmt.Printf("Application: %s (%s)\n", resp.Application.Code, resp.Application.Action)
-	fmt.Printf("Permissions: %d (%d criadas, %d atualizadas)\n", len(resp.Permissions), permsCreated, permsUpdated)
-	fmt.Printf("Roles:       %d (%d criadas, %d atualizadas)\n", len(resp.Roles), rolesCreated, rolesUpdated)
-	if len(resp.Users) > 0 {
-		fmt.Printf("Users:       %d (%d criados, %d atualizados)\n", len(resp.Users), usersCreated, usersUpdated)
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		fmt.Printf("Permissions: %d (%d criadas, %d atualizadas%s)\n", len(resp.Permissions), stats.PermsCreated, stats.PermsUpdated, pendingSuffix(pending[manifest.SectionPermissions]))
+	} else {
+		fmt.Println("Permissions: (não enviadas)")
+	}
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		fmt.Printf("Roles:       %d (%d criadas, %d atualizadas%s)\n", len(resp.Roles), stats.RolesCreated, stats.RolesUpdated, pendingSuffix(pending[manifest.SectionRoles]))
+	} else {
+		fmt.Println("Roles:       (não enviadas)")
+	}
+	if len(resp.Users) > 0 || pending[manifest.SectionUsers] > 0 {
+		fmt.Printf("Users:       %d (%d criados, %d atualizados%s)\n", len(resp.Users), stats.UsersCreated, stats.UsersUpdated, pendingSuffix(pending[manifest.SectionUsers]))
+	}
+	if len(resp.RemovedUsers) > 0 || pending[sectionRemovedUsers] > 0 {
+		fmt.Printf("Removidos:   %d (%d desativados, %d desvinculados%s)\n", len(resp.RemovedUsers), stats.UsersDeactivated, stats.UsersUnlinked, pendingSuffix(pending[sectionRemovedUsers]))
 	}
-	fmt.Println("\nSync concluído com sucesso.")
 
-	return nil
+	printSyncProblems(os.Stdout, problems)
+}
+
+// pendingSuffix formata a contagem de itens não sincronizados de uma seção
+func pendingSuffix(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", %d com problema", n)
 }
 
 // RunSyncWithExit executa RunSync e faz os.Exit apropriado em caso de erro
-func RunSyncWithExit(manifestPath string, cfg *config.Config) {
-	if err := RunSync(manifestPath, cfg); err != nil {
+func RunSyncWithExit(manifestPaths []string, cfg *config.Config, opts SyncOptions) {
+	if err := RunSync(manifestPaths, cfg, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
 		os.Exit(1)
 	}
 }
-
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// DefaultSyncConcurrency é o número padrão de syncs simultâneos no modo lote
+const DefaultSyncConcurrency = 4
+
+// syncTarget é um manifest a sincronizar e o resultado do seu sync
+type syncTarget struct {
+	Path     string
+	Manifest *manifest.AuthManifest
+	Warnings []string
+
+	// sync --users=authoritative: usuários vinculados fora do manifest
+	Removals      []client.RemovedUser
+	RemovalStates []client.UserState
+
+	Response *client.SyncResponse
+	Err      error
+	Duration time.Duration
+	Problems []syncItemProblem // Itens com erro, ignorados ou ausentes na resposta
+}
+
+// failed indica se o sync do manifest deve ser considerado falho
+func (t *syncTarget) failed(strict bool) bool {
+	return t.Err != nil || problemsFail(t.Problems, strict)
+}
+
+// loadSyncTargets carrega e valida todos os manifests antes de qualquer envio
+// Qualquer manifest inválido (ou application.code duplicado) aborta o sync inteiro
+func loadSyncTargets(paths []string, sections []string) ([]*syncTarget, error) {
+	if len(paths) == 1 {
+		m, err := manifest.LoadManifest(paths[0])
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar manifest: %w", err)
+		}
+		// Verificar dependências entre seções (sync seletivo)
+		warnings, err := manifest.CheckSectionDependencies(m, sections)
+		if err != nil {
+			return nil, err
+		}
+		return []*syncTarget{{Path: paths[0], Manifest: m, Warnings: warnings}}, nil
+	}
+
+	targets := make([]*syncTarget, 0, len(paths))
+	appPaths := make(map[string]string)
+	var loadErrors []string
+	for _, path := range paths {
+		m, err := manifest.LoadManifest(path)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("  %s: %v", path, err))
+			continue
+		}
+		if other, ok := appPaths[m.Application.Code]; ok {
+			loadErrors = append(loadErrors, fmt.Sprintf("  %s: application.code %q já declarado em %s", path, m.Application.Code, other))
+			continue
+		}
+		appPaths[m.Application.Code] = path
+
+		warnings, err := manifest.CheckSectionDependencies(m, sections)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("  %s: %v", path, err))
+			continue
+		}
+		targets = append(targets, &syncTarget{Path: path, Manifest: m, Warnings: warnings})
+	}
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("%d manifest(s) inválido(s), nenhum sync executado:\n%s", len(loadErrors), strings.Join(loadErrors, "\n"))
+	}
+	return targets, nil
+}
+
+// checkPasswordPolicy bloqueia o envio de senhas que não atendem a password_policy
+// Só verifica quando a seção users é enviada
+func checkPasswordPolicy(targets []*syncTarget, sections []string) error {
+	if !manifest.HasSection(sections, manifest.SectionUsers) {
+		return nil
+	}
+	var problems []string
+	for _, t := range targets {
+		for _, issue := range manifest.CheckPasswords(t.Manifest) {
+			problems = append(problems, fmt.Sprintf("  %s: %s", t.Path, issue))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d senha(s) não atendem a password_policy, nenhum sync executado:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// checkTenants bloqueia o envio de tenant_id malformado ou fora do registro (tenants.yaml)
+// Usuários de secretaria sem role de secretaria viram avisos em syncTarget.Warnings
+// Só verifica quando a seção users é enviada
+func checkTenants(targets []*syncTarget, sections []string, registryPath string) error {
+	if !manifest.HasSection(sections, manifest.SectionUsers) {
+		return nil
+	}
+	var problems []string
+	for _, t := range targets {
+		registry, err := manifest.LoadTenantRegistryFor(registryPath, t.Path)
+		if err != nil {
+			return err
+		}
+		errs, warnings := manifest.CheckTenants(t.Manifest, registry)
+		for _, issue := range errs {
+			problems = append(problems, fmt.Sprintf("  %s: %s", t.Path, issue))
+		}
+		for _, issue := range warnings {
+			t.Warnings = append(t.Warnings, issue.String())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d tenant_id inválido(s), nenhum sync executado:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// effectiveConcurrency limita o número de workers ao número de manifests
+func effectiveConcurrency(concurrency, total int) int {
+	if concurrency <= 0 {
+		concurrency = DefaultSyncConcurrency
+	}
+	if concurrency > total {
+		concurrency = total
+	}
+	return concurrency
+}
+
+// runSyncTargets sincroniza os manifests com um pool limitado de workers
+// Falhas são isoladas por manifest (registradas em syncTarget.Err)
+func runSyncTargets(authClient *client.AuthClient, targets []*syncTarget, opts SyncOptions) {
+	ctx := context.Background()
+
+	jobs := make(chan *syncTarget)
+	var wg sync.WaitGroup
+	for w := 0; w < effectiveConcurrency(opts.Concurrency, len(targets)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				start := time.Now()
+				t.Response, t.Err = authClient.SyncWithRemovals(ctx, t.Manifest, opts.Sections, t.Removals)
+				t.Duration = time.Since(start)
+			}
+		}()
+	}
+	for _, t := range targets {
+		jobs <- t
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// printBatchReport exibe a tabela agregada do sync em lote
+func printBatchReport(targets []*syncTarget, strict bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APLICAÇÃO\tSTATUS\tPERMISSIONS (C/A)\tROLES (C/A)\tUSERS (C/A)\tPROBLEMAS")
+
+	failed := 0
+	var total syncStats
+	totalProblems := 0
+	for _, t := range targets {
+		if t.failed(strict) {
+			failed++
+		}
+		if t.Err != nil {
+			fmt.Fprintf(w, "%s\tERRO\t-\t-\t-\t-\n", t.Manifest.Application.Code)
+			continue
+		}
+		s := computeSyncStats(t.Response)
+		total.add(s)
+		totalProblems += len(t.Problems)
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%d/%d\t%d/%d\t%d\n", t.Manifest.Application.Code, t.Response.Application.Action,
+			s.PermsCreated, s.PermsUpdated, s.RolesCreated, s.RolesUpdated, s.UsersCreated, s.UsersUpdated, len(t.Problems))
+	}
+	fmt.Fprintf(w, "TOTAL\t%d ok, %d erro(s)\t%d/%d\t%d/%d\t%d/%d\t%d\n", len(targets)-failed, failed,
+		total.PermsCreated, total.PermsUpdated, total.RolesCreated, total.RolesUpdated, total.UsersCreated, total.UsersUpdated, totalProblems)
+	w.Flush()
+
+	for _, t := range targets {
+		if len(t.Problems) > 0 {
+			fmt.Printf("\n%s:", t.Manifest.Application.Code)
+			printSyncProblems(os.Stdout, t.Problems)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Println("\nFalhas:")
+		for _, t := range targets {
+			switch {
+			case t.Err != nil:
+				fmt.Printf("  %s (%s): %v\n", t.Manifest.Application.Code, t.Path, t.Err)
+			case t.failed(strict):
+				fmt.Printf("  %s (%s): %d item(ns) não sincronizado(s)\n", t.Manifest.Application.Code, t.Path, len(t.Problems))
+			}
+		}
+	}
+}
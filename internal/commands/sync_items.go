@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Status de itens que não foram criados/atualizados pelo servidor
+const (
+	itemError   = client.ActionError   // Servidor retornou erro para o item
+	itemSkipped = client.ActionSkipped // Servidor ignorou o item
+	itemMissing = "missing"            // Item do manifest ausente na resposta
+)
+
+// syncItemProblem é um item do manifest que não foi criado/atualizado
+type syncItemProblem struct {
+	Section string `json:"section" yaml:"section"`
+	Code    string `json:"code" yaml:"code"`
+	Status  string `json:"status" yaml:"status"` // error, skipped ou missing
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// analyzeSyncResponse compara a resposta do servidor com o manifest enviado
+// Retorna os itens com erro, ignorados ou ausentes na resposta, agrupados por seção
+func analyzeSyncResponse(m *manifest.AuthManifest, resp *client.SyncResponse, sections []string) []syncItemProblem {
+	var problems []syncItemProblem
+
+	check := func(section, code, action, message string) {
+		switch action {
+		case client.ActionCreated, client.ActionUpdated:
+		case client.ActionError, client.ActionSkipped:
+			problems = append(problems, syncItemProblem{Section: section, Code: code, Status: action, Message: message})
+		default:
+			problems = append(problems, syncItemProblem{Section: section, Code: code, Status: itemError, Message: fmt.Sprintf("ação desconhecida retornada pelo servidor: %q %s", action, message)})
+		}
+	}
+
+	check("application", resp.Application.Code, resp.Application.Action, resp.Application.Message)
+
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		returned := make(map[string]bool, len(resp.Permissions))
+		for _, p := range resp.Permissions {
+			returned[p.Code] = true
+			check(manifest.SectionPermissions, p.Code, p.Action, p.Message)
+		}
+		for _, p := range m.Permissions {
+			if !returned[p.Code] {
+				problems = append(problems, syncItemProblem{Section: manifest.SectionPermissions, Code: p.Code, Status: itemMissing, Message: "não retornado na resposta do servidor"})
+			}
+		}
+	}
+
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		returned := make(map[string]bool, len(resp.Roles))
+		for _, role := range resp.Roles {
+			returned[role.Code] = true
+			check(manifest.SectionRoles, role.Code, role.Action, role.Message)
+			for _, p := range role.Permissions {
+				if p.Action == client.ActionError || p.Action == client.ActionSkipped {
+					problems = append(problems, syncItemProblem{Section: manifest.SectionRoles, Code: role.Code + " → " + p.Code, Status: p.Action, Message: p.Message})
+				}
+			}
+		}
+		for _, role := range m.Roles {
+			if !returned[role.Code] {
+				problems = append(problems, syncItemProblem{Section: manifest.SectionRoles, Code: role.Code, Status: itemMissing, Message: "não retornado na resposta do servidor"})
+			}
+		}
+	}
+
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		returned := make(map[string]bool, len(resp.Users))
+		for _, u := range resp.Users {
+			returned[strings.ToLower(u.Code)] = true
+			check(manifest.SectionUsers, u.Code, u.Action, u.Message)
+		}
+		for _, u := range m.Users {
+			if !returned[strings.ToLower(u.Email)] {
+				problems = append(problems, syncItemProblem{Section: manifest.SectionUsers, Code: u.Email, Status: itemMissing, Message: "não retornado na resposta do servidor"})
+			}
+		}
+	}
+
+	return problems
+}
+
+// problemCounts conta os problemas por status
+func problemCounts(problems []syncItemProblem) (errors, skipped, missing int) {
+	for _, p := range problems {
+		switch p.Status {
+		case itemSkipped:
+			skipped++
+		case itemMissing:
+			missing++
+		default:
+			errors++
+		}
+	}
+	return errors, skipped, missing
+}
+
+// problemsFail indica se os problemas devem falhar o sync
+// Erros sempre falham; itens ignorados ou ausentes apenas com --strict
+func problemsFail(problems []syncItemProblem, strict bool) bool {
+	errors, skipped, missing := problemCounts(problems)
+	return errors > 0 || (strict && skipped+missing > 0)
+}
+
+// printSyncProblems exibe os itens com problema agrupados por seção
+func printSyncProblems(w io.Writer, problems []syncItemProblem) {
+	if len(problems) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n⚠️  Itens não sincronizados:")
+	section := ""
+	for _, p := range problems {
+		if p.Section != section {
+			section = p.Section
+			fmt.Fprintf(w, "  %s:\n", section)
+		}
+		icon := "❌"
+		switch p.Status {
+		case itemSkipped:
+			icon = "⏭️ "
+		case itemMissing:
+			icon = "❓"
+		}
+		if p.Message != "" {
+			fmt.Fprintf(w, "    %s %s (%s): %s\n", icon, p.Code, p.Status, p.Message)
+		} else {
+			fmt.Fprintf(w, "    %s %s (%s)\n", icon, p.Code, p.Status)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/lockfile"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// syncLocks são os auth-manifest.lock dos manifests sincronizados, por caminho do lock
+type syncLocks map[string]*lockfile.Lock
+
+// loadSyncLocks carrega o lock de cada diretório de manifest
+func loadSyncLocks(targets []*syncTarget) (syncLocks, error) {
+	locks := syncLocks{}
+	for _, t := range targets {
+		path := lockfile.Path(t.Path)
+		if _, ok := locks[path]; ok {
+			continue
+		}
+		l, err := lockfile.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		locks[path] = l
+	}
+	return locks, nil
+}
+
+// lookup retorna o último sync do manifest no ambiente (nil se nunca sincronizado)
+func (locks syncLocks) lookup(env string, t *syncTarget) *lockfile.Application {
+	return locks[lockfile.Path(t.Path)].Get(env, t.Manifest.Application.Code)
+}
+
+// loadPasswordState carrega o estado local das senhas (nil, com aviso, se indisponível:
+// o sync então reenvia os usuários com senha)
+func loadPasswordState(w io.Writer) (*lockfile.PasswordState, string) {
+	path, err := config.PasswordStatePath()
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  Estado de senhas indisponível: %v\n", err)
+		return nil, ""
+	}
+	state, err := lockfile.LoadPasswordState(path)
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  Estado de senhas indisponível: %v\n", err)
+		return nil, ""
+	}
+	return state, path
+}
+
+// skipUpToDate remove os manifests já sincronizados no ambiente com o mesmo hash
+// O hash não inclui senhas: com a seção users, as senhas também precisam bater com o estado local
+// Retorna os manifests a sincronizar e os codes das aplicações puladas
+func skipUpToDate(w io.Writer, env string, locks syncLocks, passwords *lockfile.PasswordState, targets []*syncTarget, sections []string) ([]*syncTarget, []string) {
+	var pending []*syncTarget
+	var skipped []string
+	for _, t := range targets {
+		last := locks.lookup(env, t)
+		if !last.UpToDate(t.Manifest.Hash(), sections) {
+			pending = append(pending, t)
+			continue
+		}
+		if manifest.HasSection(sections, manifest.SectionUsers) && !passwords.Unchanged(env, t.Manifest) {
+			fmt.Fprintf(w, "🔑 %s: senhas alteradas (ou sem registro local do último sync), reenviando\n", t.Manifest.Application.Code)
+			pending = append(pending, t)
+			continue
+		}
+		skipped = append(skipped, t.Manifest.Application.Code)
+		fmt.Fprintf(w, "⏭️  %s: sem alterações desde o sync de %s (CLI %s)\n", t.Manifest.Application.Code, last.SyncedAt.Local().Format("02/01/2006 15:04"), last.CLIVersion)
+	}
+	return pending, skipped
+}
+
+// recordSyncLocks grava no lock os manifests sincronizados sem problemas
+// Manifests com erro ou itens não sincronizados não são registrados: o próximo sync os reenvia
+func recordSyncLocks(w io.Writer, cfg *config.Config, env string, locks syncLocks, passwords *lockfile.PasswordState, passwordsPath string, targets []*syncTarget, sections []string) {
+	changed := map[string]bool{}
+	passwordsChanged := false
+	for _, t := range targets {
+		if t.Err != nil || len(t.Problems) > 0 {
+			continue
+		}
+		path := lockfile.Path(t.Path)
+		entry := lockfile.NewApplication(t.Path, t.Manifest, sections, t.Response, locks.lookup(env, t), cliVersion())
+		locks[path].Record(env, cfg.AuthURL, t.Manifest, entry)
+		changed[path] = true
+		if passwords != nil && manifest.HasSection(sections, manifest.SectionUsers) {
+			passwords.Record(env, t.Manifest)
+			passwordsChanged = true
+		}
+	}
+
+	if passwordsChanged {
+		if err := passwords.Save(passwordsPath); err != nil {
+			fmt.Fprintf(w, "⚠️  Estado de senhas não atualizado (o próximo sync reenvia os usuários): %v\n", err)
+		}
+	}
+
+	for path := range changed {
+		if err := locks[path].Save(path); err != nil {
+			fmt.Fprintf(w, "⚠️  Lock não atualizado: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(w, "🔒 Lock atualizado: %s (ambiente %s)\n", path, env)
+	}
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Formatos de saída suportados por --output
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// SyncOutputSchemaVersion é a versão do schema de saída json/yaml do sync
+// Deve ser incrementada apenas em mudanças incompatíveis (remoção/renomeação de campos)
+const SyncOutputSchemaVersion = 1
+
+// parseOutputFormat valida o valor de --output (vazio = table)
+func parseOutputFormat(output string) (string, error) {
+	switch output {
+	case "", OutputTable:
+		return OutputTable, nil
+	case OutputJSON, OutputYAML:
+		return output, nil
+	}
+	return "", fmt.Errorf("formato de saída inválido %q (válidos: table, json, yaml)", output)
+}
+
+// SyncOutput é o documento emitido por 'sync --output json|yaml'
+type SyncOutput struct {
+	SchemaVersion int                `json:"schema_version" yaml:"schema_version"`
+	Status        string             `json:"status" yaml:"status"` // "success", "partial" ou "error"
+	URL           string             `json:"url" yaml:"url"`
+	Sections      []string           `json:"sections" yaml:"sections"`
+	StartedAt     time.Time          `json:"started_at" yaml:"started_at"`
+	DurationMs    int64              `json:"duration_ms" yaml:"duration_ms"`
+	Totals        syncStats          `json:"totals" yaml:"totals"`
+	Applications  []SyncOutputResult `json:"applications" yaml:"applications"`
+	Skipped       []string           `json:"skipped,omitempty" yaml:"skipped,omitempty"` // Aplicações em dia segundo o auth-manifest.lock
+}
+
+// SyncOutputResult é o resultado do sync de um manifest
+type SyncOutputResult struct {
+	Application  string               `json:"application" yaml:"application"`
+	Manifest     string               `json:"manifest" yaml:"manifest"`
+	ManifestHash string               `json:"manifest_hash" yaml:"manifest_hash"`
+	Status       string               `json:"status" yaml:"status"` // "success", "partial" ou "error"
+	Error        string               `json:"error,omitempty" yaml:"error,omitempty"`
+	DurationMs   int64                `json:"duration_ms" yaml:"duration_ms"`
+	Stats        syncStats            `json:"stats" yaml:"stats"`
+	Problems     []syncItemProblem    `json:"problems" yaml:"problems"` // Itens com erro, ignorados ou ausentes
+	Response     *client.SyncResponse `json:"response" yaml:"response"`
+}
+
+// buildSyncOutput monta o documento de saída a partir dos resultados do sync
+func buildSyncOutput(cfg *config.Config, opts SyncOptions, targets []*syncTarget, startedAt time.Time, duration time.Duration) SyncOutput {
+	out := SyncOutput{
+		SchemaVersion: SyncOutputSchemaVersion,
+		Status:        "success",
+		URL:           cfg.AuthURL,
+		Sections:      opts.Sections,
+		StartedAt:     startedAt.UTC(),
+		DurationMs:    duration.Milliseconds(),
+		Applications:  make([]SyncOutputResult, 0, len(targets)),
+	}
+	if out.Sections == nil {
+		out.Sections = []string{"application", "permissions", "roles", "users"}
+	} else {
+		out.Sections = append([]string{"application"}, out.Sections...)
+	}
+
+	for _, t := range targets {
+		result := SyncOutputResult{
+			Application:  t.Manifest.Application.Code,
+			Manifest:     t.Path,
+			ManifestHash: "sha256:" + t.Manifest.Hash(),
+			Status:       "success",
+			DurationMs:   t.Duration.Milliseconds(),
+			Response:     t.Response,
+		}
+		if t.Err != nil {
+			result.Error = t.Err.Error()
+		} else {
+			result.Stats = computeSyncStats(t.Response)
+			out.Totals.add(result.Stats)
+		}
+		if t.Problems != nil {
+			result.Problems = t.Problems
+		} else {
+			result.Problems = []syncItemProblem{}
+		}
+
+		switch {
+		case t.failed(opts.Strict):
+			result.Status = "error"
+			out.Status = "error"
+		case len(t.Problems) > 0:
+			result.Status = "partial"
+			if out.Status == "success" {
+				out.Status = "partial"
+			}
+		}
+		out.Applications = append(out.Applications, result)
+	}
+	return out
+}
+
+// writeSyncOutput serializa o documento no formato escolhido
+func writeSyncOutput(w io.Writer, format string, out SyncOutput) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(out); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("formato de saída inválido %q", format)
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Modos de 'sync --users'
+const (
+	UsersMerge         = "merge"         // Padrão: usuários fora do manifest não são alterados
+	UsersAuthoritative = "authoritative" // O manifest é a lista completa de usuários da aplicação
+)
+
+// sectionRemovedUsers agrupa os problemas de removed_users nos relatórios
+const sectionRemovedUsers = "removed_users"
+
+// validateUsersMode verifica --users e --removed-users
+func validateUsersMode(opts SyncOptions) error {
+	switch opts.Users {
+	case "", UsersMerge:
+		return nil
+	case UsersAuthoritative:
+	default:
+		return fmt.Errorf("--users inválido %q (válidos: merge, authoritative)", opts.Users)
+	}
+	switch opts.RemovedUsers {
+	case client.RemoveDeactivate, client.RemoveUnlink:
+	default:
+		return fmt.Errorf("--removed-users inválido %q (válidos: deactivate, unlink)", opts.RemovedUsers)
+	}
+	if !manifest.HasSection(opts.Sections, manifest.SectionUsers) {
+		return fmt.Errorf("--users=authoritative exige a seção users (remova-a de --skip ou inclua em --only)")
+	}
+	return nil
+}
+
+// planUserRemovals consulta os usuários vinculados a cada aplicação e preenche
+// syncTarget.Removals com os que não estão no manifest (emails sem diferenciar maiúsculas)
+// Ao desativar, usuários já inativos são ignorados
+func planUserRemovals(authClient *client.AuthClient, targets []*syncTarget, action string) error {
+	for _, t := range targets {
+		state, err := authClient.GetApplication(context.Background(), t.Manifest.Application.Code)
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			continue // Aplicação nova: nenhum usuário vinculado
+		}
+		if err != nil {
+			return fmt.Errorf("%s: erro ao consultar usuários vinculados: %w", t.Manifest.Application.Code, err)
+		}
+
+		listed := make(map[string]bool, len(t.Manifest.Users))
+		for _, u := range t.Manifest.Users {
+			listed[strings.ToLower(u.Email)] = true
+		}
+		for _, u := range state.Users {
+			if listed[strings.ToLower(u.Email)] || (action == client.RemoveDeactivate && !u.Active) {
+				continue
+			}
+			t.Removals = append(t.Removals, client.RemovedUser{Email: u.Email, Action: action})
+			t.RemovalStates = append(t.RemovalStates, u)
+		}
+	}
+	return nil
+}
+
+// printRemovalPlan exibe os usuários que serão desativados ou desvinculados
+// Retorna o total de usuários afetados
+func printRemovalPlan(w io.Writer, targets []*syncTarget, action string) int {
+	verb := "desativados"
+	if action == client.RemoveUnlink {
+		verb = "desvinculados da aplicação"
+	}
+
+	total := 0
+	for _, t := range targets {
+		total += len(t.Removals)
+	}
+	if total == 0 {
+		fmt.Fprintln(w, "👥 --users=authoritative: nenhum usuário vinculado fora do manifest")
+		return 0
+	}
+
+	fmt.Fprintf(w, "👥 --users=authoritative: %d usuário(s) fora do manifest serão %s:\n", total, verb)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, t := range targets {
+		for _, u := range t.RemovalStates {
+			status := "ativo"
+			if !u.Active {
+				status = "inativo"
+			}
+			fmt.Fprintf(tw, "   - %s\t%s\t%s\t%s\troles: %s\n", t.Manifest.Application.Code, u.Email, u.Name, status, strings.Join(u.Roles, ", "))
+		}
+	}
+	tw.Flush()
+	return total
+}
+
+// confirmUserRemovals pede confirmação antes de desativar/desvincular usuários
+func confirmUserRemovals(total int, action string, yes bool) error {
+	if yes {
+		fmt.Fprintln(os.Stderr, "   Remoção confirmada via --yes")
+		return nil
+	}
+	if !isInteractive() {
+		return fmt.Errorf("--users=authoritative vai alterar %d usuário(s): use --yes para confirmar fora de um terminal interativo", total)
+	}
+
+	verb := "Desativar"
+	if action == client.RemoveUnlink {
+		verb = "Desvincular"
+	}
+	var proceed bool
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("%s %d usuário(s)?", verb, total),
+		Default: false,
+	}, &proceed, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)); err != nil {
+		return fmt.Errorf("erro ao ler confirmação: %w", err)
+	}
+	if !proceed {
+		return fmt.Errorf("operação cancelada")
+	}
+	return nil
+}
+
+// analyzeRemovals confere se o servidor confirmou cada remoção pedida
+// Remoção sem resposta é erro (não apenas "ausente"): o servidor pode não suportar
+// removed_users, e a auditoria não pode contar com um usuário que continua ativo
+func analyzeRemovals(removals []client.RemovedUser, resp *client.SyncResponse) []syncItemProblem {
+	if len(removals) == 0 {
+		return nil
+	}
+	results := make(map[string]client.SyncResultDTO, len(resp.RemovedUsers))
+	for _, r := range resp.RemovedUsers {
+		results[strings.ToLower(r.Code)] = r
+	}
+
+	var problems []syncItemProblem
+	for _, removal := range removals {
+		expected := client.ActionDeactivated
+		if removal.Action == client.RemoveUnlink {
+			expected = client.ActionUnlinked
+		}
+		result, ok := results[strings.ToLower(removal.Email)]
+		switch {
+		case !ok:
+			problems = append(problems, syncItemProblem{Section: sectionRemovedUsers, Code: removal.Email, Status: itemError, Message: "remoção não confirmada pelo servidor (sem suporte a removed_users?)"})
+		case result.Action != expected:
+			message := result.Message
+			if message == "" {
+				message = fmt.Sprintf("esperado %q, servidor retornou %q", expected, result.Action)
+			}
+			problems = append(problems, syncItemProblem{Section: sectionRemovedUsers, Code: removal.Email, Status: itemError, Message: message})
+		}
+	}
+	return problems
+}
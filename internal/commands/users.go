@@ -0,0 +1,483 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+// Campos do manifest aceitos em 'users import --map campo=coluna'
+const (
+	importFieldEmail    = "email"
+	importFieldName     = "name"
+	importFieldTenantID = "tenant_id"
+	importFieldRoles    = "roles"
+	importFieldPassword = "password"
+	// Referência à senha fora da planilha: env:VARIAVEL ou file:caminho (sempre criptografada no manifest)
+	importFieldPasswordRef = "password_ref"
+)
+
+// importColumnAliases são os cabeçalhos reconhecidos sem --map (comparados via Slugify)
+var importColumnAliases = map[string][]string{
+	importFieldEmail:       {"email", "e-mail"},
+	importFieldName:        {"name", "nome", "nome completo"},
+	importFieldTenantID:    {"tenant_id", "tenant", "unidade", "secretaria"},
+	importFieldRoles:       {"roles", "role", "perfis", "perfil", "papeis"},
+	importFieldPassword:    {"password", "senha"},
+	importFieldPasswordRef: {"password_ref", "senha_ref", "referencia da senha"},
+}
+
+// UsersImportOptions contém as opções de 'users import'
+type UsersImportOptions struct {
+	Mapping           map[string]string // campo → cabeçalho da coluna (--map email=E-mail,roles=Perfis)
+	Delimiter         string            // Separador do CSV; vazio = detecta (',', ';' ou tab)
+	DryRun            bool              // Apenas exibe o resumo, sem gravar o manifest
+	GeneratePasswords bool              // Gera senhas para usuários novos sem senha na planilha
+	PasswordsFile     string            // Arquivo (0600) com as senhas geradas; vazio = exibe no stdout
+	TenantsPath       string            // Registro de tenants (padrão: tenants.yaml ao lado do manifest)
+
+	// Encrypt grava as senhas da planilha criptografadas (ENC[...]); as geradas e as de
+	// password_ref são sempre criptografadas
+	Encrypt        bool
+	EncryptOptions EncryptOptions // Destinatários (padrão: chaves locais, ver 'keygen')
+}
+
+// importRow é uma linha da planilha já convertida em usuário
+type importRow struct {
+	Line int
+	User manifest.User
+}
+
+// importRejection é uma linha recusada e o motivo
+type importRejection struct {
+	Line   int
+	Email  string
+	Reason string
+}
+
+// RunUsersImport acrescenta ou atualiza usuários do manifest a partir de um CSV
+// (export de planilha). Cada linha é validada (email, roles declaradas, tenant_id,
+// password_policy); linhas inválidas são recusadas e as demais mescladas no manifest,
+// preservando comentários. Emails são comparados sem diferenciar maiúsculas.
+func RunUsersImport(manifestPath, csvPath string, opts UsersImportOptions) error {
+	if opts.GeneratePasswords && opts.PasswordsFile != "" {
+		if _, err := os.Stat(opts.PasswordsFile); err == nil {
+			return fmt.Errorf("arquivo de senhas %s já existe (não será sobrescrito)", opts.PasswordsFile)
+		}
+	}
+
+	header, records, err := readImportCSV(csvPath, opts.Delimiter)
+	if err != nil {
+		return err
+	}
+	columns, err := mapImportColumns(header, opts.Mapping)
+	if err != nil {
+		return err
+	}
+	_, hasPasswordRefs := columns[importFieldPasswordRef]
+
+	// Senhas geradas e referenciadas nunca vão em texto claro para o manifest
+	var recipients []secrets.Recipient
+	if opts.Encrypt || opts.GeneratePasswords || hasPasswordRefs {
+		var err error
+		if recipients, err = encryptRecipients(opts.EncryptOptions); err != nil {
+			return fmt.Errorf("as senhas são gravadas criptografadas no manifest: %w", err)
+		}
+	}
+
+	doc, err := manifest.LoadDocument(manifestPath)
+	if err != nil {
+		return err
+	}
+	// Sem decifrar: a validação só precisa das roles, da política e dos emails
+	m, err := doc.Manifest()
+	if err != nil {
+		return err
+	}
+	registry, err := manifest.LoadTenantRegistryFor(opts.TenantsPath, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	declaredRoles := make(map[string]bool, len(m.Roles))
+	for _, role := range m.Roles {
+		declaredRoles[role.Code] = true
+	}
+	policy := m.EffectivePasswordPolicy()
+	// Chaves locais: senhas atuais criptografadas são comparadas decifradas, para que
+	// reimportar a mesma planilha não regrave os blobs (a criptografia é aleatória)
+	identities, err := secrets.LoadIdentities(nil)
+	if err != nil {
+		return err
+	}
+
+	var (
+		rows      []importRow
+		rejected  []importRejection
+		warnings  []string
+		generated []generatedPassword
+	)
+	seen := make(map[string]int) // email em minúsculas → linha
+	for i, record := range records {
+		line := i + 2 // linha 1 é o cabeçalho
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue // linha em branco
+		}
+
+		email := cell(importFieldEmail)
+		reject := func(reason string) {
+			rejected = append(rejected, importRejection{Line: line, Email: email, Reason: reason})
+		}
+		if !validImportEmail(email) {
+			reject(fmt.Sprintf("email inválido %q", email))
+			continue
+		}
+		key := strings.ToLower(email)
+		if first, ok := seen[key]; ok {
+			reject(fmt.Sprintf("email duplicado (linha %d)", first))
+			continue
+		}
+		seen[key] = line
+
+		u := manifest.User{Email: email, Name: cell(importFieldName), Password: cell(importFieldPassword)}
+		existing := doc.FindUser(email)
+		if existing < 0 && u.Name == "" {
+			reject("nome obrigatório para usuário novo")
+			continue
+		}
+
+		if _, ok := columns[importFieldRoles]; ok && cell(importFieldRoles) != "" {
+			u.Roles = splitImportRoles(cell(importFieldRoles))
+			var undeclared []string
+			for _, role := range u.Roles {
+				if !declaredRoles[role] {
+					undeclared = append(undeclared, role)
+				}
+			}
+			if len(undeclared) > 0 {
+				reject(fmt.Sprintf("role(s) não declarada(s) em roles: %s", strings.Join(undeclared, ", ")))
+				continue
+			}
+		} else if existing < 0 {
+			u.Roles = []string{}
+		}
+
+		if tenant := cell(importFieldTenantID); tenant != "" {
+			u.TenantID = &tenant
+		}
+		// Roles atuais contam para o aviso de secretaria quando a planilha não traz roles
+		check := u
+		if check.Roles == nil && existing >= 0 {
+			check.Roles = m.Users[existing].Roles
+		}
+		tenantErrors, tenantWarnings := manifest.CheckTenants(&manifest.AuthManifest{Users: []manifest.User{check}}, registry)
+		if len(tenantErrors) > 0 {
+			reject(tenantErrors[0].Message)
+			continue
+		}
+		for _, w := range tenantWarnings {
+			warnings = append(warnings, fmt.Sprintf("linha %d (%s): %s", line, email, w.Message))
+		}
+
+		encrypt := opts.Encrypt
+		if ref := cell(importFieldPasswordRef); ref != "" {
+			if u.Password != "" {
+				reject("password e password_ref preenchidos: use apenas um")
+				continue
+			}
+			password, err := resolvePasswordRef(ref, filepath.Dir(csvPath))
+			if err != nil {
+				reject(err.Error())
+				continue
+			}
+			u.Password = password
+			encrypt = true
+		}
+
+		switch {
+		case secrets.IsEncrypted(u.Password):
+			// Senha já criptografada (ver 'encrypt'): mantida como está
+		case u.Password != "":
+			if problems := policy.Check(u.Password, email); len(problems) > 0 {
+				reject("senha fraca: " + strings.Join(problems, ", "))
+				continue
+			}
+			if existing >= 0 && samePassword(m.Users[existing].Password, u.Password, email, identities) {
+				u.Password = "" // mantém o valor atual
+				break
+			}
+			if encrypt {
+				if u.Password, err = secrets.Encrypt(u.Password, secrets.PasswordContext(email), recipients); err != nil {
+					return err
+				}
+			}
+		case existing < 0 && opts.GeneratePasswords:
+			password, err := policy.GeneratePassword()
+			if err != nil {
+				return err
+			}
+			generated = append(generated, generatedPassword{Email: email, Password: password})
+			if u.Password, err = secrets.Encrypt(password, secrets.PasswordContext(email), recipients); err != nil {
+				return err
+			}
+		}
+
+		rows = append(rows, importRow{Line: line, User: u})
+	}
+
+	// Mesclar no documento (preserva comentários e a ordem dos usuários existentes)
+	var added, updated, unchanged []string
+	plaintext := false
+	for _, row := range rows {
+		if row.User.Password != "" && !secrets.IsEncrypted(row.User.Password) {
+			plaintext = true
+		}
+		if index := doc.FindUser(row.User.Email); index >= 0 {
+			changed, err := doc.UpdateUser(index, row.User)
+			if err != nil {
+				return err
+			}
+			if changed {
+				updated = append(updated, row.User.Email)
+			} else {
+				unchanged = append(unchanged, row.User.Email)
+			}
+			continue
+		}
+		if err := doc.AddUser(row.User); err != nil {
+			return err
+		}
+		added = append(added, row.User.Email)
+	}
+
+	printImportSummary(csvPath, added, updated, unchanged, rejected, warnings)
+
+	if opts.DryRun {
+		fmt.Println("\n(dry-run: manifest não alterado)")
+	} else if len(added)+len(updated) > 0 {
+		if err := doc.Save(manifestPath); err != nil {
+			return err
+		}
+		fmt.Printf("\n✅ Manifest atualizado: %s\n", manifestPath)
+		if plaintext {
+			fmt.Println("   ⚠️  Senhas da planilha gravadas em texto claro: use --encrypt ou execute 'encrypt' antes do commit")
+		}
+		if err := deliverGeneratedPasswords(generated, opts.PasswordsFile); err != nil {
+			return err
+		}
+	}
+
+	if len(rejected) > 0 {
+		return fmt.Errorf("%d linha(s) recusada(s)", len(rejected))
+	}
+	return nil
+}
+
+// readImportCSV lê o CSV (com ou sem BOM) e retorna o cabeçalho e as linhas
+func readImportCSV(path, delimiter string) ([]string, [][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM do Excel
+
+	comma, err := importDelimiter(data, delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s está vazio", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	return header, records, nil
+}
+
+// importDelimiter retorna o separador informado ou o mais frequente no cabeçalho
+// Planilhas exportadas em pt-BR costumam usar ';'
+func importDelimiter(data []byte, delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+	case `\t`, "tab":
+		return '\t', nil
+	default:
+		runes := []rune(delimiter)
+		if len(runes) != 1 {
+			return 0, fmt.Errorf("--delimiter deve ser um único caractere (atual: %q)", delimiter)
+		}
+		return runes[0], nil
+	}
+
+	firstLine, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', ';', '\t'} {
+		if count := strings.Count(firstLine, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best, nil
+}
+
+// mapImportColumns associa cada campo ao índice da coluna
+// --map tem precedência sobre os cabeçalhos conhecidos (importColumnAliases)
+func mapImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[manifest.Slugify(h)] = i
+	}
+
+	columns := make(map[string]int)
+	for field, column := range mapping {
+		if _, ok := importColumnAliases[field]; !ok {
+			return nil, fmt.Errorf("--map: campo desconhecido %q (válidos: email, name, tenant_id, roles, password, password_ref)", field)
+		}
+		i, ok := index[manifest.Slugify(column)]
+		if !ok {
+			return nil, fmt.Errorf("--map: coluna %q não encontrada no cabeçalho (%s)", column, strings.Join(header, ", "))
+		}
+		columns[field] = i
+	}
+	for field, aliases := range importColumnAliases {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		for _, alias := range aliases {
+			if i, ok := index[manifest.Slugify(alias)]; ok {
+				columns[field] = i
+				break
+			}
+		}
+	}
+
+	if _, ok := columns[importFieldEmail]; !ok {
+		return nil, fmt.Errorf("coluna de email não encontrada no cabeçalho (%s); use --map email=<coluna>", strings.Join(header, ", "))
+	}
+	return columns, nil
+}
+
+// samePassword indica se a senha atual do manifest (texto claro ou ENC[...]) é igual à nova
+// Sem uma chave que decifre a atual, a senha é tratada como alterada
+func samePassword(current, password, email string, identities []secrets.Identity) bool {
+	if !secrets.IsEncrypted(current) {
+		return current == password
+	}
+	plaintext, err := secrets.Decrypt(current, secrets.PasswordContext(email), identities)
+	return err == nil && plaintext == password
+}
+
+// resolvePasswordRef lê a senha referenciada na planilha (a senha não fica no CSV)
+//   - env:VARIAVEL: variável de ambiente
+//   - file:caminho: conteúdo do arquivo (relativo ao diretório do CSV), sem a quebra de linha final
+func resolvePasswordRef(ref, baseDir string) (string, error) {
+	kind, target, _ := strings.Cut(ref, ":")
+	target = strings.TrimSpace(target)
+	if target == "" {
+		kind = ""
+	}
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "env":
+		password := os.Getenv(target)
+		if password == "" {
+			return "", fmt.Errorf("password_ref: variável %s não definida", target)
+		}
+		return password, nil
+	case "file":
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(baseDir, target)
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			return "", fmt.Errorf("password_ref: %w", err)
+		}
+		password := strings.TrimRight(string(data), "\r\n")
+		if password == "" {
+			return "", fmt.Errorf("password_ref: %s está vazio", target)
+		}
+		return password, nil
+	}
+	return "", fmt.Errorf("password_ref inválido %q (use env:VARIAVEL ou file:caminho)", ref)
+}
+
+// splitImportRoles separa as roles de uma célula ("a|b", "a, b" ou "a;b")
+func splitImportRoles(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '|' || r == ',' || r == ';' || r == '\n'
+	})
+	seen := make(map[string]bool, len(fields))
+	roles := make([]string, 0, len(fields))
+	for _, f := range fields {
+		role := strings.TrimSpace(f)
+		if role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// validImportEmail faz uma verificação simples do formato do email
+func validImportEmail(email string) bool {
+	user, domain, ok := strings.Cut(email, "@")
+	return ok && user != "" && strings.Contains(domain, ".") && !strings.Contains(domain, "@") && !strings.ContainsAny(email, " \t")
+}
+
+// printImportSummary exibe o resumo de linhas adicionadas, atualizadas e recusadas
+func printImportSummary(csvPath string, added, updated, unchanged []string, rejected []importRejection, warnings []string) {
+	fmt.Printf("📥 Importação de %s\n", csvPath)
+	fmt.Printf("   Adicionados:   %d\n", len(added))
+	fmt.Printf("   Atualizados:   %d\n", len(updated))
+	fmt.Printf("   Sem alteração: %d\n", len(unchanged))
+	fmt.Printf("   Recusados:     %d\n", len(rejected))
+
+	for _, email := range added {
+		fmt.Printf("   + %s\n", email)
+	}
+	for _, email := range updated {
+		fmt.Printf("   ~ %s\n", email)
+	}
+	if len(rejected) > 0 {
+		sort.SliceStable(rejected, func(i, j int) bool { return rejected[i].Line < rejected[j].Line })
+		fmt.Println("\n❌ Linhas recusadas:")
+		for _, r := range rejected {
+			fmt.Printf("   linha %d (%s): %s\n", r.Line, r.Email, r.Reason)
+		}
+	}
+	for _, w := range warnings {
+		fmt.Printf("⚠️  %s\n", w)
+	}
+}
+
+// RunUsersImportWithExit executa RunUsersImport e faz os.Exit apropriado em caso de erro
+func RunUsersImportWithExit(manifestPath, csvPath string, opts UsersImportOptions) {
+	if err := RunUsersImport(manifestPath, csvPath, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
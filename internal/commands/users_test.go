@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+const importManifest = `apiVersion: sagep-auth/v1
+application:
+  code: sagep-biopass
+  name: Biopass
+permissions:
+  - code: biopass.devices.read
+    subject: biopass.devices
+    action: read
+roles:
+  - code: biopass.viewer
+    name: Visualizador
+    permissions:
+      - biopass.devices.read
+`
+
+func TestUsersImportEncryptsPasswords(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	key, err := secrets.GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath := filepath.Join(dir, "auth-manifest.yaml")
+	csvPath := filepath.Join(dir, "operadores.csv")
+	const password = "Planilha#Segura2026"
+	if err := os.WriteFile(manifestPath, []byte(importManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csv := "email,nome,perfis,senha\noperador@sagep.com.br,Operador,biopass.viewer," + password + "\nnovo@sagep.com.br,Novo,biopass.viewer,\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := UsersImportOptions{
+		Encrypt:           true,
+		GeneratePasswords: true,
+		PasswordsFile:     filepath.Join(dir, "senhas.csv"),
+		EncryptOptions:    EncryptOptions{Recipients: []string{key.String()}},
+	}
+	if err := RunUsersImport(manifestPath, csvPath, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), password) || strings.Count(string(data), "ENC[") != 2 {
+		t.Fatalf("as duas senhas deveriam estar criptografadas:\n%s", data)
+	}
+
+	t.Setenv("SAGEP_AUTH_KEY", key.String())
+	m, err := manifest.LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Users[0].Password != password || m.Users[1].Password == "" {
+		t.Fatalf("senhas decifradas inesperadas: %+v", m.Users)
+	}
+}
+
+func TestUsersImportPasswordRef(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	key, err := secrets.GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const fromEnv, fromFile = "Variavel#Segura2026", "Arquivo#Seguro2026"
+	t.Setenv("SENHA_OPERADOR", fromEnv)
+	if err := os.WriteFile(filepath.Join(dir, "leitor.txt"), []byte(fromFile+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(dir, "auth-manifest.yaml")
+	csvPath := filepath.Join(dir, "operadores.csv")
+	if err := os.WriteFile(manifestPath, []byte(importManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csv := "email;nome;perfis;senha;senha_ref\n" +
+		"operador@sagep.com.br;Operador;biopass.viewer;;env:SENHA_OPERADOR\n" +
+		"leitor@sagep.com.br;Leitor;biopass.viewer;;file:leitor.txt\n" +
+		"ausente@sagep.com.br;Ausente;biopass.viewer;;env:SENHA_INEXISTENTE\n" +
+		"ambas@sagep.com.br;Ambas;biopass.viewer;Planilha#Segura2026;env:SENHA_OPERADOR\n"
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sem --encrypt: senhas referenciadas são criptografadas mesmo assim
+	err = RunUsersImport(manifestPath, csvPath, UsersImportOptions{EncryptOptions: EncryptOptions{Recipients: []string{key.String()}}})
+	if err == nil || !strings.Contains(err.Error(), "2 linha(s) recusada(s)") {
+		t.Fatalf("esperava 2 linhas recusadas, obteve %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), fromEnv) || strings.Contains(string(data), fromFile) || strings.Count(string(data), "ENC[") != 2 {
+		t.Fatalf("as senhas referenciadas deveriam estar criptografadas:\n%s", data)
+	}
+
+	t.Setenv("SAGEP_AUTH_KEY", key.String())
+	m, err := manifest.LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Users) != 2 || m.Users[0].Password != fromEnv || m.Users[1].Password != fromFile {
+		t.Fatalf("usuários importados inesperados: %+v", m.Users)
+	}
+}
+
+func TestUsersImportKeepsUnchangedEncryptedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	key, err := secrets.GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SAGEP_AUTH_KEY", key.String())
+
+	manifestPath := filepath.Join(dir, "auth-manifest.yaml")
+	csvPath := filepath.Join(dir, "operadores.csv")
+	if err := os.WriteFile(manifestPath, []byte(importManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeCSV := func(password string) {
+		t.Helper()
+		csv := "email,nome,perfis,senha\noperador@sagep.com.br,Operador,biopass.viewer," + password + "\n"
+		if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	opts := UsersImportOptions{Encrypt: true}
+
+	writeCSV("Planilha#Segura2026")
+	if err := RunUsersImport(manifestPath, csvPath, opts); err != nil {
+		t.Fatal(err)
+	}
+	first := read()
+
+	// Mesma planilha: o blob aleatório não pode ser regravado
+	if err := RunUsersImport(manifestPath, csvPath, opts); err != nil {
+		t.Fatal(err)
+	}
+	if read() != first {
+		t.Fatalf("reimportar a mesma senha não deveria alterar o manifest:\n%s", read())
+	}
+
+	// Senha nova: o blob é substituído
+	writeCSV("Rotacionada#Segura2027")
+	if err := RunUsersImport(manifestPath, csvPath, opts); err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Users[0].Password != "Rotacionada#Segura2027" {
+		t.Fatalf("a senha trocada deveria ter sido gravada: %q", m.Users[0].Password)
+	}
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/report"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+// ValidateOptions contém as opções do comando validate
+type ValidateOptions struct {
+	GlossaryPath string   // Glossário usado pelo lint de convenções
+	TenantsPath  string   // Registro de tenants (padrão: tenants.yaml ao lado do manifest)
+	Reports      []string // Relatórios a gerar (--report junit.xml, --report summary.md)
+}
+
+// RunValidate valida um ou mais manifests sem contatar o servidor
+// Verifica a estrutura (mesmas regras do sync), as referências entre roles/permissions/users,
+// as senhas dos usuários (password_policy), o tenant_id dos usuários e, quando o manifest declara o bloco conventions, as convenções de nomenclatura
+func RunValidate(manifestPaths []string, opts ValidateOptions) error {
+	if err := checkReportPaths(opts.Reports); err != nil {
+		return err
+	}
+
+	paths, err := manifest.ResolvePaths(manifestPaths)
+	if err != nil {
+		return err
+	}
+
+	r := &report.Report{Command: "validate", GeneratedAt: time.Now()}
+	failed := 0
+	for _, path := range paths {
+		suite, warnings := validateManifestFile(path, opts)
+		_, suiteFailed, _ := suite.Counts()
+		failed += suiteFailed
+
+		if suiteFailed == 0 {
+			fmt.Printf("✅ %s: %s (%d itens)\n", path, suite.Name, len(suite.Cases))
+		} else {
+			fmt.Printf("❌ %s: %s\n", path, suite.Name)
+			for _, c := range suite.Cases {
+				if c.Status == report.StatusFailed {
+					fmt.Printf("   - %s %s: %s\n", c.Section, c.Name, c.Message)
+				}
+			}
+		}
+		for _, warning := range warnings {
+			fmt.Printf("   ⚠️  %s\n", warning)
+		}
+		r.Suites = append(r.Suites, suite)
+	}
+
+	if err := writeReports(os.Stdout, opts.Reports, r); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d problema(s) encontrado(s)", failed)
+	}
+	return nil
+}
+
+// validateManifestFile valida um manifest e retorna os itens verificados
+// e os avisos (problemas que não reprovam o manifest)
+func validateManifestFile(path string, opts ValidateOptions) (report.Suite, []string) {
+	start := time.Now()
+	suite := report.Suite{Name: path, File: path}
+
+	m, err := manifest.LoadManifestWithoutKeys(path)
+	if err != nil {
+		suite.Cases = append(suite.Cases, report.Case{Section: "manifest", Name: path, Status: report.StatusFailed, Message: err.Error()})
+		suite.Duration = time.Since(start)
+		return suite, nil
+	}
+	suite.Name = m.Application.Code
+	suite.Cases = append(suite.Cases, report.Case{Section: "manifest", Name: path, Status: report.StatusPassed})
+
+	// Problemas por item: referências e convenções
+	problems := make(map[string]map[int][]string)
+	addProblem := func(section string, index int, msg string) {
+		if problems[section] == nil {
+			problems[section] = make(map[int][]string)
+		}
+		problems[section][index] = append(problems[section][index], msg)
+	}
+
+	for _, issue := range manifest.CheckReferences(m) {
+		addProblem(issue.Section, issue.Index, issue.Message)
+	}
+	for _, issue := range manifest.CheckPasswords(m) {
+		addProblem(issue.Section, issue.Index, issue.Message)
+	}
+
+	var warnings []string
+	if sealed := encryptedPasswords(m); sealed > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d senha(s) cifrada(s) não verificada(s) pela password_policy: nenhuma chave local as decifra", sealed))
+	}
+	if m.APIVersion == manifest.APIVersionLegacy {
+		warnings = append(warnings, fmt.Sprintf("manifest sem apiVersion: execute 'migrate' para declarar %s", manifest.CurrentAPIVersion))
+	}
+	if registry, err := manifest.LoadTenantRegistryFor(opts.TenantsPath, path); err != nil {
+		suite.Cases = append(suite.Cases, report.Case{Section: "manifest", Name: "tenants", Status: report.StatusFailed, Message: err.Error()})
+	} else {
+		tenantErrors, tenantWarnings := manifest.CheckTenants(m, registry)
+		for _, issue := range tenantErrors {
+			addProblem(issue.Section, issue.Index, issue.Message)
+		}
+		for _, issue := range tenantWarnings {
+			warnings = append(warnings, issue.String())
+		}
+	}
+
+	if m.Conventions != nil {
+		glossary, err := manifest.LoadGlossaryFor(opts.GlossaryPath, path)
+		if err != nil {
+			suite.Cases = append(suite.Cases, report.Case{Section: "manifest", Name: "glossary", Status: report.StatusFailed, Message: err.Error()})
+		} else if issues, err := manifest.Lint(m, glossary); err == nil {
+			for _, issue := range issues {
+				addProblem(manifest.SectionPermissions, issue.Index, fmt.Sprintf("%s %q deveria ser %q (conventions)", issue.Field, issue.Current, issue.Expected))
+			}
+		}
+	}
+
+	itemCase := func(section string, index int, name string) report.Case {
+		c := report.Case{Section: section, Name: name, Status: report.StatusPassed}
+		if msgs := problems[section][index]; len(msgs) > 0 {
+			c.Status = report.StatusFailed
+			c.Message = strings.Join(msgs, "; ")
+		}
+		return c
+	}
+	for i, p := range m.Permissions {
+		suite.Cases = append(suite.Cases, itemCase(manifest.SectionPermissions, i, p.Code))
+	}
+	for i, role := range m.Roles {
+		suite.Cases = append(suite.Cases, itemCase(manifest.SectionRoles, i, role.Code))
+	}
+	for i, u := range m.Users {
+		suite.Cases = append(suite.Cases, itemCase(manifest.SectionUsers, i, u.Email))
+	}
+
+	suite.Duration = time.Since(start)
+	return suite, warnings
+}
+
+// RunValidateWithExit executa RunValidate e faz os.Exit apropriado em caso de erro
+func RunValidateWithExit(manifestPaths []string, opts ValidateOptions) {
+	if err := RunValidate(manifestPaths, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// encryptedPasswords conta as senhas que continuaram cifradas (sem a chave local)
+func encryptedPasswords(m *manifest.AuthManifest) int {
+	count := 0
+	for _, u := range m.Users {
+		if secrets.IsEncrypted(u.Password) {
+			count++
+		}
+	}
+	return count
+}
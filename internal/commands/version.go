@@ -0,0 +1,21 @@
+package commands
+
+import "runtime/debug"
+
+// Version é a versão do CLI, definida no build:
+//
+//	go build -ldflags "-X github.com/BrBit-Sistemas/sagep-auth-cli/internal/commands.Version=v1.2.0"
+//
+// Sem ldflags, usa a versão do módulo (go install ...@v1.2.0) ou "dev"
+var Version = ""
+
+// cliVersion retorna a versão gravada no lock
+func cliVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
 	"github.com/joho/godotenv"
 )
 
@@ -14,12 +17,91 @@ type Config struct {
 	AuthURL    string
 	AuthToken  string // JWT token (uso normal)
 	AuthSecret string // Secret para HMAC (bootstrap)
+	Profile    string // Perfil ativo (config.yaml) e das credenciais salvas pelo login (padrão: default)
+	Protected  bool   // Perfil marcado com protected: true (exige confirmação antes de alterar o servidor)
+
+	profile *Profile // Perfil do config.yaml (nil se não houver)
+
+	SignatureVersion string // Esquema HMAC: v1 (padrão) ou v2 (SAGEP_AUTH_SIGNATURE_VERSION)
+	KeyID            string // Identificador do secret para rotação (SAGEP_AUTH_KEY_ID, opcional)
+
+	// Conexão: flags > perfil > .env > env vars do sistema
+	CACert             string        // CA adicional em PEM (SAGEP_AUTH_CA_CERT)
+	ClientCert         string        // Certificado do cliente para mTLS (SAGEP_AUTH_CLIENT_CERT)
+	ClientKey          string        // Chave do cliente para mTLS (SAGEP_AUTH_CLIENT_KEY)
+	InsecureSkipVerify bool          // Não verifica o certificado do servidor (SAGEP_AUTH_INSECURE_SKIP_VERIFY)
+	Timeout            time.Duration // Tempo máximo por requisição (SAGEP_AUTH_TIMEOUT, padrão 30s)
+	ConnectTimeout     time.Duration // Conexão + handshake TLS (SAGEP_AUTH_CONNECT_TIMEOUT, padrão 10s)
+	Proxy              string        // URL do proxy ou "none" (SAGEP_AUTH_PROXY; vazio = HTTPS_PROXY/NO_PROXY)
+
+	MaxRetries int           // Retries em falhas transitórias (SAGEP_AUTH_MAX_RETRIES, padrão 3)
+	RetryDelay time.Duration // Intervalo inicial do backoff (SAGEP_AUTH_RETRY_DELAY, padrão 500ms)
 }
 
-// LoadConfig carrega a configuração a partir de flags, arquivo .env e variáveis de ambiente
-// Ordem de precedência: flags > .env > env vars do sistema
+// LoadConfig carrega a configuração a partir de flags, perfil, arquivo .env e variáveis de ambiente
+// Ordem de precedência: flags > perfil ativo (config.yaml) > .env > env vars do sistema
 // As variáveis SAGEP_AUTH_URL e SAGEP_AUTH_SECRET são obrigatórias
-func LoadConfig(authURLFlag, authTokenFlag, authSecretFlag string) (*Config, error) {
+func LoadConfig(profileFlag, authURLFlag, authTokenFlag, authSecretFlag string) (*Config, error) {
+	cfg, err := LoadBaseConfig(profileFlag, authURLFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.profile != nil {
+		// Com perfil ativo, SAGEP_AUTH_TOKEN/SAGEP_AUTH_SECRET globais não são usados:
+		// credenciais de outro ambiente não podem ser enviadas para a URL do perfil
+		cfg.AuthToken = firstNonEmpty(authTokenFlag, cfg.profile.Token, envValue(cfg.profile.TokenEnv))
+		cfg.AuthSecret = firstNonEmpty(authSecretFlag, cfg.profile.Secret, envValue(cfg.profile.SecretEnv))
+		if cfg.AuthSecret == "" {
+			if cfg.profile.SecretEnv != "" {
+				return nil, fmt.Errorf("perfil %q: a variável %s (secret_env) não está definida. Configure-a ou use --secret", cfg.Profile, cfg.profile.SecretEnv)
+			}
+			return nil, fmt.Errorf("perfil %q sem secret. Defina secret ou secret_env no perfil, ou use --secret (SAGEP_AUTH_SECRET não é usado com perfil ativo)", cfg.Profile)
+		}
+	} else {
+		// Token: flag > .env > env vars do sistema (opcional)
+		// Sem token explícito, os comandos usam as credenciais salvas pelo login
+		cfg.AuthToken = firstNonEmpty(authTokenFlag, os.Getenv("SAGEP_AUTH_TOKEN"))
+
+		// Secret: flag > .env > env vars do sistema (obrigatório)
+		cfg.AuthSecret = firstNonEmpty(authSecretFlag, os.Getenv("SAGEP_AUTH_SECRET"))
+		if cfg.AuthSecret == "" {
+			return nil, fmt.Errorf("SAGEP_AUTH_SECRET é obrigatório. Configure via --secret, perfil, arquivo .env ou variável de ambiente")
+		}
+	}
+
+	// Assinatura HMAC: perfil > .env > env vars do sistema
+	signatureVersion, err := client.ParseSignatureVersion(firstNonEmpty(cfg.profileValue(func(p *Profile) string { return p.SignatureVersion }), os.Getenv("SAGEP_AUTH_SIGNATURE_VERSION")))
+	if err != nil {
+		return nil, fmt.Errorf("SAGEP_AUTH_SIGNATURE_VERSION: %w", err)
+	}
+	cfg.SignatureVersion = signatureVersion
+	cfg.KeyID = strings.TrimSpace(firstNonEmpty(cfg.profileValue(func(p *Profile) string { return p.KeyID }), os.Getenv("SAGEP_AUTH_KEY_ID")))
+
+	// Retries: .env > env vars do sistema (flags do comando sync sobrescrevem depois)
+	cfg.MaxRetries = 3
+	if v := os.Getenv("SAGEP_AUTH_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SAGEP_AUTH_MAX_RETRIES inválido: %s", v)
+		}
+		cfg.MaxRetries = n
+	}
+	cfg.RetryDelay = 500 * time.Millisecond
+	if v := os.Getenv("SAGEP_AUTH_RETRY_DELAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("SAGEP_AUTH_RETRY_DELAY inválido (ex: 500ms, 2s): %s", v)
+		}
+		cfg.RetryDelay = d
+	}
+
+	return cfg, nil
+}
+
+// LoadBaseConfig carrega o arquivo .env, o perfil ativo e a URL do auth, sem exigir o secret
+// Usado por comandos que não assinam requisições com HMAC (ex: login)
+func LoadBaseConfig(profileFlag, authURLFlag string) (*Config, error) {
 	// Tentar carregar arquivo .env (se existir)
 	// Primeiro tenta no diretório atual, depois procura a raiz do projeto
 	envPath := ".env"
@@ -41,39 +123,111 @@ func LoadConfig(authURLFlag, authTokenFlag, authSecretFlag string) (*Config, err
 		}
 	}
 
-	cfg := &Config{}
+	// Perfil: --profile > SAGEP_AUTH_PROFILE > current_profile (depois do .env, que pode definir SAGEP_AUTH_PROFILE)
+	name, profile, _, err := ResolveProfile(profileFlag)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{Profile: name, profile: profile}
+	if profile != nil {
+		cfg.Protected = profile.Protected
+	}
+
+	// URL do auth: flag > perfil > .env > env vars do sistema
+	url := firstNonEmpty(authURLFlag, cfg.profileValue(func(p *Profile) string { return p.URL }), os.Getenv("SAGEP_AUTH_URL"))
+	if url == "" {
+		return nil, fmt.Errorf("SAGEP_AUTH_URL é obrigatória. Configure via --url, perfil (--profile), arquivo .env ou variável de ambiente")
+	}
+	cfg.AuthURL = strings.TrimSuffix(url, "/")
 
-	// URL do auth: flag > .env > env vars do sistema
-	if authURLFlag != "" {
-		cfg.AuthURL = strings.TrimSuffix(authURLFlag, "/")
-	} else {
-		envURL := os.Getenv("SAGEP_AUTH_URL")
-		if envURL == "" {
-			return nil, fmt.Errorf("SAGEP_AUTH_URL é obrigatória. Configure via --url, arquivo .env ou variável de ambiente")
+	if err := cfg.loadConnection(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadConnection carrega TLS, timeouts e proxy: perfil > .env > env vars do sistema
+// (flags são aplicadas depois, pelo main)
+func (c *Config) loadConnection() error {
+	c.CACert = firstNonEmpty(c.profileValue(func(p *Profile) string { return p.CACert }), os.Getenv("SAGEP_AUTH_CA_CERT"))
+	c.ClientCert = firstNonEmpty(c.profileValue(func(p *Profile) string { return p.ClientCert }), os.Getenv("SAGEP_AUTH_CLIENT_CERT"))
+	c.ClientKey = firstNonEmpty(c.profileValue(func(p *Profile) string { return p.ClientKey }), os.Getenv("SAGEP_AUTH_CLIENT_KEY"))
+	c.Proxy = firstNonEmpty(c.profileValue(func(p *Profile) string { return p.Proxy }), os.Getenv("SAGEP_AUTH_PROXY"))
+	for _, path := range []*string{&c.CACert, &c.ClientCert, &c.ClientKey} {
+		*path = expandHome(*path)
+	}
+
+	if c.profile != nil && c.profile.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	} else if v := os.Getenv("SAGEP_AUTH_INSECURE_SKIP_VERIFY"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("SAGEP_AUTH_INSECURE_SKIP_VERIFY inválido (use true/false): %s", v)
 		}
-		cfg.AuthURL = strings.TrimSuffix(envURL, "/")
+		c.InsecureSkipVerify = insecure
 	}
 
-	// Token: flag > .env > env vars do sistema (opcional)
-	if authTokenFlag != "" {
-		cfg.AuthToken = authTokenFlag
-	} else {
-		cfg.AuthToken = os.Getenv("SAGEP_AUTH_TOKEN")
+	var err error
+	if c.Timeout, err = parseDurationSetting("timeout", "SAGEP_AUTH_TIMEOUT",
+		firstNonEmpty(c.profileValue(func(p *Profile) string { return p.Timeout }), os.Getenv("SAGEP_AUTH_TIMEOUT"))); err != nil {
+		return err
 	}
+	if c.ConnectTimeout, err = parseDurationSetting("connect_timeout", "SAGEP_AUTH_CONNECT_TIMEOUT",
+		firstNonEmpty(c.profileValue(func(p *Profile) string { return p.ConnectTimeout }), os.Getenv("SAGEP_AUTH_CONNECT_TIMEOUT"))); err != nil {
+		return err
+	}
+	return nil
+}
 
-	// Secret: flag > .env > env vars do sistema (obrigatório)
-	if authSecretFlag != "" {
-		cfg.AuthSecret = authSecretFlag
-	} else {
-		cfg.AuthSecret = os.Getenv("SAGEP_AUTH_SECRET")
+// expandHome expande "~/" no início de caminhos vindos do perfil (o shell não os expande)
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
 	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
 
-	// Validar que o secret está configurado (obrigatório)
-	if cfg.AuthSecret == "" {
-		return nil, fmt.Errorf("SAGEP_AUTH_SECRET é obrigatório. Configure via --secret, arquivo .env ou variável de ambiente")
+// parseDurationSetting interpreta uma duração do perfil ou do ambiente (vazio = 0, usa o padrão)
+func parseDurationSetting(profileKey, envKey, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s / %s inválido (ex: 30s, 1m): %s", profileKey, envKey, value)
 	}
+	return d, nil
+}
 
-	return cfg, nil
+// profileValue retorna um campo do perfil ativo ("" se não houver perfil)
+func (c *Config) profileValue(field func(*Profile) string) string {
+	if c.profile == nil {
+		return ""
+	}
+	return field(c.profile)
+}
+
+// envValue lê a variável nomeada (vazio se o nome não foi configurado)
+func envValue(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// firstNonEmpty retorna o primeiro valor não vazio
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // FindProjectRoot procura a raiz do projeto procurando por .env ou go.mod
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withProfiles grava um config.yaml temporário e isola o teste de .env do repositório
+func withProfiles(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	t.Setenv("SAGEP_AUTH_CONFIG", path)
+	t.Setenv("SAGEP_AUTH_PROFILE", "")
+	t.Setenv("SAGEP_AUTH_URL", "https://auth-hml.sagep.com.br")
+	t.Setenv("SAGEP_AUTH_TOKEN", "token-hml")
+	t.Setenv("SAGEP_AUTH_SECRET", "secret-hml")
+}
+
+const testProfiles = `
+current_profile: producao
+profiles:
+  producao:
+    url: https://auth.sagep.com.br
+  producao-env:
+    url: https://auth.sagep.com.br
+    secret_env: SAGEP_AUTH_SECRET_PRODUCAO
+`
+
+func TestLoadConfigProfileIgnoresGlobalCredentials(t *testing.T) {
+	withProfiles(t, testProfiles)
+
+	_, err := LoadConfig("", "", "", "")
+	if err == nil || !strings.Contains(err.Error(), `perfil "producao" sem secret`) {
+		t.Fatalf("perfil sem secret não deve usar SAGEP_AUTH_SECRET: %v", err)
+	}
+
+	cfg, err := LoadConfig("", "", "", "secret-flag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthSecret != "secret-flag" || cfg.AuthToken != "" {
+		t.Fatalf("com perfil ativo, token/secret vêm só do perfil e das flags: token=%q secret=%q", cfg.AuthToken, cfg.AuthSecret)
+	}
+}
+
+func TestLoadConfigProfileSecretEnv(t *testing.T) {
+	withProfiles(t, testProfiles)
+
+	if _, err := LoadConfig("producao-env", "", "", ""); err == nil || !strings.Contains(err.Error(), "SAGEP_AUTH_SECRET_PRODUCAO") {
+		t.Fatalf("esperava erro citando secret_env: %v", err)
+	}
+
+	t.Setenv("SAGEP_AUTH_SECRET_PRODUCAO", "secret-prd")
+	cfg, err := LoadConfig("producao-env", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthSecret != "secret-prd" {
+		t.Fatalf("secret deveria vir de secret_env, obteve %q", cfg.AuthSecret)
+	}
+}
+
+func TestLoadConfigWithoutProfileUsesEnv(t *testing.T) {
+	withProfiles(t, "profiles: {}\n")
+
+	cfg, err := LoadConfig("", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthSecret != "secret-hml" || cfg.AuthToken != "token-hml" {
+		t.Fatalf("sem perfil, token/secret vêm das variáveis de ambiente: token=%q secret=%q", cfg.AuthToken, cfg.AuthSecret)
+	}
+}
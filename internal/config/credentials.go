@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// DefaultProfile é o perfil usado quando nenhum outro é selecionado
+const DefaultProfile = "default"
+
+// credentialsExpirySkew antecipa a expiração do token para evitar usá-lo no limite
+const credentialsExpirySkew = 30 * time.Second
+
+// profileNamePattern restringe nomes de perfil (usados como nome de arquivo)
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Credentials é o token JWT obtido pelo comando login, salvo por perfil
+type Credentials struct {
+	AuthURL    string    `json:"auth_url"`
+	Email      string    `json:"email"`
+	AppCode    string    `json:"application_code,omitempty"` // Aplicação informada no login (reutilizada ao renovar)
+	Token      string    `json:"token"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"` // Zero = expiração desconhecida
+	ObtainedAt time.Time `json:"obtained_at"`
+}
+
+// Expired indica se o token expirou (ou expira nos próximos segundos)
+func (c *Credentials) Expired() bool {
+	if c.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Now().Add(credentialsExpirySkew).After(c.ExpiresAt)
+}
+
+// ValidateProfileName verifica se o nome do perfil pode ser usado como nome de arquivo
+func ValidateProfileName(profile string) error {
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("nome de perfil inválido %q (use letras, números, '.', '-' ou '_')", profile)
+	}
+	return nil
+}
+
+// ConfigDir retorna o diretório de configuração do CLI (~/.config/sagep-auth)
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("diretório de configuração do usuário não encontrado: %w", err)
+	}
+	return filepath.Join(dir, "sagep-auth"), nil
+}
+
+// CredentialsPath retorna o arquivo de credenciais do perfil
+// (~/.config/sagep-auth/credentials/<perfil>.json)
+func CredentialsPath(profile string) (string, error) {
+	if err := ValidateProfileName(profile); err != nil {
+		return "", err
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials", profile+".json"), nil
+}
+
+// PasswordStatePath retorna o estado local das senhas sincronizadas
+// (~/.config/sagep-auth/sync-passwords.json), que detecta troca de senhas fora do lock
+func PasswordStatePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sync-passwords.json"), nil
+}
+
+// LoadCredentials carrega as credenciais do perfil
+// Retorna nil (sem erro) se o perfil ainda não fez login
+func LoadCredentials(profile string) (*Credentials, error) {
+	path, err := CredentialsPath(profile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler credenciais %s: %w", path, err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse das credenciais %s: %w", path, err)
+	}
+	return &creds, nil
+}
+
+// SaveCredentials grava as credenciais do perfil com permissão 0600
+// O diretório é criado com 0700; um arquivo existente tem a permissão corrigida
+func SaveCredentials(profile string, creds *Credentials) (string, error) {
+	path, err := CredentialsPath(profile)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar credenciais: %w", err)
+	}
+
+	// Gravar em arquivo temporário e renomear, para nunca deixar o token legível por outros
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+profile+"-*.json")
+	if err != nil {
+		return "", fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("erro ao ajustar permissão das credenciais: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("erro ao gravar credenciais %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile é um ambiente do sagep-auth (ex: local, homologação, produção)
+// Campos vazios não sobrescrevem .env / variáveis de ambiente, exceto token e secret:
+// com perfil ativo, eles vêm apenas do perfil (ou de token_env/secret_env) e das flags
+type Profile struct {
+	URL              string `yaml:"url"`
+	Token            string `yaml:"token,omitempty"`
+	Secret           string `yaml:"secret,omitempty"`
+	TokenEnv         string `yaml:"token_env,omitempty"`  // Variável de ambiente com o token deste perfil
+	SecretEnv        string `yaml:"secret_env,omitempty"` // Variável de ambiente com o secret deste perfil
+	SignatureVersion string `yaml:"signature_version,omitempty"`
+	KeyID            string `yaml:"key_id,omitempty"`
+	Protected        bool   `yaml:"protected,omitempty"` // Exige confirmação antes de alterar o servidor
+	Description      string `yaml:"description,omitempty"`
+
+	// Conexão (TLS, timeouts e proxy)
+	CACert             string `yaml:"ca_cert,omitempty"`
+	ClientCert         string `yaml:"client_cert,omitempty"`
+	ClientKey          string `yaml:"client_key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	Timeout            string `yaml:"timeout,omitempty"`         // Duração (ex: 30s)
+	ConnectTimeout     string `yaml:"connect_timeout,omitempty"` // Duração (ex: 10s)
+	Proxy              string `yaml:"proxy,omitempty"`           // URL do proxy ou "none"
+}
+
+// ProfilesFile é o arquivo ~/.config/sagep-auth/config.yaml (estilo kubeconfig)
+type ProfilesFile struct {
+	CurrentProfile string             `yaml:"current_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// ProfilesPath retorna o caminho do arquivo de perfis
+// SAGEP_AUTH_CONFIG sobrescreve o padrão (~/.config/sagep-auth/config.yaml)
+func ProfilesPath() (string, error) {
+	if path := os.Getenv("SAGEP_AUTH_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// LoadProfiles carrega o arquivo de perfis
+// Retorna um ProfilesFile vazio (sem erro) se o arquivo não existir
+func LoadProfiles() (*ProfilesFile, string, error) {
+	path, err := ProfilesPath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	pf := &ProfilesFile{Profiles: map[string]Profile{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return pf, path, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, pf); err != nil {
+		return nil, "", fmt.Errorf("erro ao fazer parse de %s: %w", path, err)
+	}
+	if pf.Profiles == nil {
+		pf.Profiles = map[string]Profile{}
+	}
+
+	for name, p := range pf.Profiles {
+		if err := ValidateProfileName(name); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+		if p.URL == "" {
+			return nil, "", fmt.Errorf("%s: perfil %q sem url", path, name)
+		}
+	}
+	if pf.CurrentProfile != "" {
+		if _, ok := pf.Profiles[pf.CurrentProfile]; !ok {
+			return nil, "", fmt.Errorf("%s: current_profile %q não está em profiles", path, pf.CurrentProfile)
+		}
+	}
+	return pf, path, nil
+}
+
+// Names retorna os nomes dos perfis em ordem alfabética
+func (pf *ProfilesFile) Names() []string {
+	names := make([]string, 0, len(pf.Profiles))
+	for name := range pf.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetCurrentProfile grava current_profile no arquivo de perfis
+// Edita o YAML como árvore de nós, preservando comentários e a ordem dos perfis
+func SetCurrentProfile(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("erro ao fazer parse de %s: %w", path, err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: formato inválido (esperado um mapa com profiles)", path)
+	}
+
+	mapping := root.Content[0]
+	updated := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "current_profile" {
+			mapping.Content[i+1].SetString(name)
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "current_profile"}
+		value := &yaml.Node{}
+		value.SetString(name)
+		mapping.Content = append([]*yaml.Node{key, value}, mapping.Content...)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return fmt.Errorf("erro ao serializar %s: %w", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("erro ao serializar %s: %w", path, err)
+	}
+	// O arquivo pode conter secrets e tokens: manter legível apenas pelo usuário
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return os.Chmod(path, 0o600)
+}
+
+// ResolveProfile determina o perfil ativo
+// Ordem de precedência: --profile > SAGEP_AUTH_PROFILE > current_profile do config.yaml
+// Retorna o nome, o perfil (nil se não houver config.yaml / perfil) e a origem da seleção
+func ResolveProfile(profileFlag string) (string, *Profile, string, error) {
+	pf, path, err := LoadProfiles()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	name, source := profileFlag, "--profile"
+	if name == "" {
+		name, source = os.Getenv("SAGEP_AUTH_PROFILE"), "SAGEP_AUTH_PROFILE"
+	}
+	if name == "" {
+		name, source = pf.CurrentProfile, "current_profile"
+	}
+	if name == "" {
+		return DefaultProfile, nil, "", nil
+	}
+
+	if err := ValidateProfileName(name); err != nil {
+		return "", nil, "", err
+	}
+	p, ok := pf.Profiles[name]
+	if !ok && name == DefaultProfile {
+		// "default" sem entrada no config.yaml: apenas .env / variáveis de ambiente
+		return DefaultProfile, nil, "", nil
+	}
+	if !ok {
+		if len(pf.Profiles) == 0 {
+			return "", nil, "", fmt.Errorf("perfil %q (%s) não encontrado: %s não existe ou não tem profiles", name, source, path)
+		}
+		return "", nil, "", fmt.Errorf("perfil %q (%s) não encontrado em %s (disponíveis: %s)", name, source, path, strings.Join(pf.Names(), ", "))
+	}
+	return name, &p, source, nil
+}
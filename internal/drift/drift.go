@@ -0,0 +1,204 @@
+// Package drift compara o estado de uma aplicação no servidor com o manifest
+//
+// Alterações feitas fora do CLI (ex: roles e vínculos de usuários editados pela API
+// administrativa) fazem o servidor divergir do manifest. Cada diferença é classificada
+// como missing (no manifest, ausente no servidor), extra (no servidor, fora do manifest)
+// ou modified (nos dois, com campos diferentes). Usuários inativos fora do manifest
+// (desativados por sync --users=authoritative) são o estado esperado e não contam como extra
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Tipos de diferença
+const (
+	KindMissing  = "missing"  // No manifest, ausente no servidor
+	KindExtra    = "extra"    // No servidor, fora do manifest
+	KindModified = "modified" // Nos dois, com campos diferentes
+)
+
+// Difference é um item em que o servidor diverge do manifest
+type Difference struct {
+	Section string   `json:"section" yaml:"section"` // application, permissions, roles ou users
+	Code    string   `json:"code" yaml:"code"`       // Code do item (email, para usuários)
+	Kind    string   `json:"kind" yaml:"kind"`       // missing, extra ou modified
+	ID      string   `json:"id,omitempty" yaml:"id,omitempty"`
+	Details []string `json:"details,omitempty" yaml:"details,omitempty"` // Campos divergentes (modified)
+}
+
+// Compare compara o manifest com o estado do servidor nas seções informadas (nil = todas)
+// Senhas e tenant_id não são comparados: o servidor não expõe senhas e só aplica
+// tenant_id na criação do usuário
+func Compare(m *manifest.AuthManifest, state *client.ApplicationState, sections []string) []Difference {
+	var diffs []Difference
+
+	var details []string
+	details = field(details, "name", m.Application.Name, state.Application.Name)
+	details = field(details, "description", m.Application.Description, state.Application.Description)
+	if len(details) > 0 {
+		diffs = append(diffs, Difference{Section: "application", Code: m.Application.Code, Kind: KindModified, ID: state.Application.ID, Details: details})
+	}
+
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		diffs = append(diffs, comparePermissions(m, state)...)
+	}
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		diffs = append(diffs, compareRoles(m, state)...)
+	}
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		diffs = append(diffs, compareUsers(m, state)...)
+	}
+	return diffs
+}
+
+// Missing retorna a diferença de uma aplicação que não existe no servidor
+func Missing(m *manifest.AuthManifest) []Difference {
+	return []Difference{{Section: "application", Code: m.Application.Code, Kind: KindMissing}}
+}
+
+func comparePermissions(m *manifest.AuthManifest, state *client.ApplicationState) []Difference {
+	var diffs []Difference
+	server := make(map[string]client.PermissionState, len(state.Permissions))
+	for _, p := range state.Permissions {
+		server[p.Code] = p
+	}
+
+	declared := make(map[string]bool, len(m.Permissions))
+	for _, p := range m.Permissions {
+		declared[p.Code] = true
+		s, ok := server[p.Code]
+		if !ok {
+			diffs = append(diffs, Difference{Section: manifest.SectionPermissions, Code: p.Code, Kind: KindMissing})
+			continue
+		}
+		var details []string
+		details = field(details, "subject", p.Subject, s.Subject)
+		details = field(details, "action", p.Action, s.Action)
+		details = field(details, "description", p.Description, s.Description)
+		details = field(details, "conditions", p.Conditions, s.Conditions)
+		if len(details) > 0 {
+			diffs = append(diffs, Difference{Section: manifest.SectionPermissions, Code: p.Code, Kind: KindModified, ID: s.ID, Details: details})
+		}
+	}
+	for _, s := range state.Permissions {
+		if !declared[s.Code] {
+			diffs = append(diffs, Difference{Section: manifest.SectionPermissions, Code: s.Code, Kind: KindExtra, ID: s.ID})
+		}
+	}
+	return diffs
+}
+
+func compareRoles(m *manifest.AuthManifest, state *client.ApplicationState) []Difference {
+	var diffs []Difference
+	server := make(map[string]client.RoleState, len(state.Roles))
+	for _, r := range state.Roles {
+		server[r.Code] = r
+	}
+
+	declared := make(map[string]bool, len(m.Roles))
+	for _, r := range m.Roles {
+		declared[r.Code] = true
+		s, ok := server[r.Code]
+		if !ok {
+			diffs = append(diffs, Difference{Section: manifest.SectionRoles, Code: r.Code, Kind: KindMissing})
+			continue
+		}
+		var details []string
+		details = field(details, "name", r.Name, s.Name)
+		details = field(details, "description", r.Description, s.Description)
+		if r.System != s.System {
+			details = append(details, fmt.Sprintf("system: %t no manifest, %t no servidor", r.System, s.System))
+		}
+		details = append(details, setDetails("permission", m.ExpandRolePermissions(r), s.Permissions)...)
+		if len(details) > 0 {
+			diffs = append(diffs, Difference{Section: manifest.SectionRoles, Code: r.Code, Kind: KindModified, ID: s.ID, Details: details})
+		}
+	}
+	for _, s := range state.Roles {
+		if !declared[s.Code] {
+			diffs = append(diffs, Difference{Section: manifest.SectionRoles, Code: s.Code, Kind: KindExtra, ID: s.ID})
+		}
+	}
+	return diffs
+}
+
+func compareUsers(m *manifest.AuthManifest, state *client.ApplicationState) []Difference {
+	var diffs []Difference
+	server := make(map[string]client.UserState, len(state.Users))
+	for _, u := range state.Users {
+		server[strings.ToLower(u.Email)] = u
+	}
+
+	declared := make(map[string]bool, len(m.Users))
+	for _, u := range m.Users {
+		email := strings.ToLower(u.Email)
+		declared[email] = true
+		s, ok := server[email]
+		if !ok {
+			diffs = append(diffs, Difference{Section: manifest.SectionUsers, Code: u.Email, Kind: KindMissing})
+			continue
+		}
+		var details []string
+		details = field(details, "name", u.Name, s.Name)
+		if !s.Active {
+			details = append(details, "active: inativo no servidor")
+		}
+		details = append(details, setDetails("role", u.Roles, s.Roles)...)
+		if len(details) > 0 {
+			diffs = append(diffs, Difference{Section: manifest.SectionUsers, Code: u.Email, Kind: KindModified, ID: s.ID, Details: details})
+		}
+	}
+	for _, s := range state.Users {
+		// Inativo e fora do manifest: resultado de --removed-users=deactivate, não é drift
+		if !declared[strings.ToLower(s.Email)] && s.Active {
+			diffs = append(diffs, Difference{Section: manifest.SectionUsers, Code: s.Email, Kind: KindExtra, ID: s.ID})
+		}
+	}
+	return diffs
+}
+
+// field registra um campo com valor diferente entre manifest e servidor
+func field(details []string, name, local, remote string) []string {
+	if local == remote {
+		return details
+	}
+	return append(details, fmt.Sprintf("%s: %q no manifest, %q no servidor", name, local, remote))
+}
+
+// setDetails compara duas listas sem considerar a ordem
+// "+" = só no servidor, "-" = só no manifest
+func setDetails(label string, local, remote []string) []string {
+	inLocal := make(map[string]bool, len(local))
+	for _, v := range local {
+		inLocal[v] = true
+	}
+	inRemote := make(map[string]bool, len(remote))
+	for _, v := range remote {
+		inRemote[v] = true
+	}
+
+	var details []string
+	for _, v := range sorted(remote) {
+		if !inLocal[v] {
+			details = append(details, fmt.Sprintf("+ %s %s (só no servidor)", label, v))
+		}
+	}
+	for _, v := range sorted(local) {
+		if !inRemote[v] {
+			details = append(details, fmt.Sprintf("- %s %s (ausente no servidor)", label, v))
+		}
+	}
+	return details
+}
+
+func sorted(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
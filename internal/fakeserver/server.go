@@ -0,0 +1,345 @@
+// Package fakeserver implementa um sagep-auth em memória, para testes e desenvolvimento local
+//
+// Endpoints:
+//   - POST /v1/applications/sync  (HMAC v1/v2 ou JWT)
+//   - GET  /v1/applications/{code} (HMAC v1/v2 ou JWT)
+//   - POST /v1/authenticate       (email e senha de usuários sincronizados)
+//   - GET  /me                    (JWT; regras CASL.js do usuário)
+//
+// As regras de sync (ordem de processamento, wildcards, role master, tenant_id)
+// seguem docs/REGRAS_NEGOCIO.md. Os dados são perdidos ao encerrar o processo
+package fakeserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Valores padrão do servidor
+const (
+	DefaultTokenTTL = time.Hour
+	DefaultMaxSkew  = 5 * time.Minute
+)
+
+// Options configura o servidor fake
+type Options struct {
+	Secret     string            // BOOTSTRAP_SECRET (usado quando X-Key-Id não é enviado)
+	KeySecrets map[string]string // Secrets por X-Key-Id (rotação de chaves)
+	TokenTTL   time.Duration     // Validade dos tokens de /v1/authenticate (0 = DefaultTokenTTL)
+	MaxSkew    time.Duration     // Janela aceita para X-Timestamp (0 = DefaultMaxSkew)
+	Logger     *log.Logger       // Log de requisições (nil = sem log)
+}
+
+// session é um token emitido por /v1/authenticate
+type session struct {
+	Email     string
+	AppCode   string
+	ExpiresAt time.Time
+}
+
+// Server é o sagep-auth em memória
+type Server struct {
+	opts Options
+
+	mu       sync.Mutex
+	apps     map[string]*application
+	users    map[string]*user
+	sessions map[string]*session
+	nonces   map[string]time.Time // Nonces da assinatura v2 já usados (proteção contra replay)
+}
+
+// New cria um servidor fake vazio
+func New(opts Options) *Server {
+	if opts.TokenTTL <= 0 {
+		opts.TokenTTL = DefaultTokenTTL
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = DefaultMaxSkew
+	}
+	return &Server{
+		opts:     opts,
+		apps:     map[string]*application{},
+		users:    map[string]*user{},
+		sessions: map[string]*session{},
+		nonces:   map[string]time.Time{},
+	}
+}
+
+// Apply sincroniza um manifest diretamente no store (ex: dados iniciais do mock-server)
+// Como no sync do CLI, todos os usuários ficam ativos: active: false não chega ao payload
+func (s *Server) Apply(m *manifest.AuthManifest) (*client.SyncResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := make([]syncUser, 0, len(m.Users))
+	for _, u := range m.Users {
+		users = append(users, syncUser{User: u})
+	}
+	return s.apply(&syncPayload{
+		Application: m.Application,
+		Permissions: m.Permissions,
+		Roles:       m.Roles,
+		Users:       users,
+	})
+}
+
+// Handler retorna o http.Handler com os endpoints do sagep-auth
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/applications/sync", s.handleSync)
+	mux.HandleFunc("/v1/applications/", s.handleGetApplication)
+	mux.HandleFunc("/v1/authenticate", s.handleAuthenticate)
+	mux.HandleFunc("/me", s.handleMe)
+	if s.opts.Logger == nil {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		mux.ServeHTTP(rec, r)
+		s.opts.Logger.Printf("%s %s → %d (%s)", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// statusRecorder guarda o status HTTP para o log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "método não permitido")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "erro ao ler body")
+		return
+	}
+
+	var payload syncPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON inválido: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, err := s.authorize(r, body)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if sess != nil && sess.AppCode != "" && sess.AppCode != payload.Application.Code {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("token é da aplicação %s", sess.AppCode))
+		return
+	}
+
+	resp, err := s.apply(&payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetApplication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "método não permitido")
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/v1/applications/")
+	if code == "" || strings.Contains(code, "/") {
+		writeError(w, http.StatusNotFound, "não encontrado")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, err := s.authorize(r, nil)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if sess != nil && sess.AppCode != "" && sess.AppCode != code {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("token é da aplicação %s", sess.AppCode))
+		return
+	}
+
+	state, ok := s.state(code)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("aplicação %s não encontrada", code))
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *Server) handleAuthenticate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "método não permitido")
+		return
+	}
+	var req client.AuthenticateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON inválido: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[strings.ToLower(strings.TrimSpace(req.Email))]
+	if !ok || !u.Active || u.Password != req.Password {
+		writeError(w, http.StatusUnauthorized, "email ou senha inválidos")
+		return
+	}
+	if req.ApplicationCode != "" {
+		if _, linked := u.AppRoles[req.ApplicationCode]; !linked {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("usuário não vinculado à aplicação %s", req.ApplicationCode))
+			return
+		}
+	}
+
+	expiresAt := time.Now().Add(s.opts.TokenTTL)
+	token := newToken(u, req.ApplicationCode, expiresAt)
+	s.sessions[token] = &session{Email: u.Email, AppCode: req.ApplicationCode, ExpiresAt: expiresAt}
+	writeJSON(w, http.StatusOK, client.AuthenticateResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.UTC(),
+		ExpiresIn: int64(s.opts.TokenTTL / time.Second),
+	})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "método não permitido")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, err := s.bearer(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	u := s.users[sess.Email]
+
+	appCode := sess.AppCode
+	if appCode == "" {
+		appCode = r.URL.Query().Get("application")
+	}
+	if appCode == "" {
+		writeError(w, http.StatusBadRequest, "token sem aplicação: informe ?application={code}")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":          u.ID,
+		"email":       u.Email,
+		"name":        u.Name,
+		"tenant_id":   u.TenantID,
+		"application": appCode,
+		"roles":       append([]string{}, u.AppRoles[appCode]...),
+		"abilities":   s.abilities(u, appCode),
+	})
+}
+
+// authorize valida JWT (Authorization: Bearer) ou HMAC (X-Signature)
+// Retorna a sessão do token (nil quando autenticado por HMAC)
+// O chamador deve segurar s.mu
+func (s *Server) authorize(r *http.Request, body []byte) (*session, error) {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return s.bearer(r)
+	}
+
+	keyID := r.Header.Get("X-Key-Id")
+	secret := s.opts.Secret
+	if keyID != "" {
+		var ok bool
+		if secret, ok = s.opts.KeySecrets[keyID]; !ok {
+			return nil, fmt.Errorf("X-Key-Id %q desconhecido", keyID)
+		}
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("servidor sem BOOTSTRAP_SECRET: use Authorization: Bearer")
+	}
+	if err := client.VerifySignature(r, body, secret, s.opts.MaxSkew); err != nil {
+		return nil, err
+	}
+
+	// Assinatura v2: cada nonce só pode ser usado uma vez dentro da janela do timestamp
+	if nonce := r.Header.Get("X-Nonce"); nonce != "" && r.Header.Get("X-Signature-Version") == client.SignatureV2 {
+		now := time.Now()
+		for n, seen := range s.nonces {
+			if now.Sub(seen) > 2*s.opts.MaxSkew {
+				delete(s.nonces, n)
+			}
+		}
+		if _, used := s.nonces[nonce]; used {
+			return nil, fmt.Errorf("X-Nonce já utilizado (replay)")
+		}
+		s.nonces[nonce] = now
+	}
+	return nil, nil
+}
+
+// bearer valida o token JWT emitido por /v1/authenticate
+// O chamador deve segurar s.mu
+func (s *Server) bearer(r *http.Request) (*session, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	sess, ok := s.sessions[token]
+	if !ok || token == "" {
+		return nil, fmt.Errorf("token inválido")
+	}
+	if time.Now().After(sess.ExpiresAt) {
+		delete(s.sessions, token)
+		return nil, fmt.Errorf("token expirado")
+	}
+	return sess, nil
+}
+
+// newToken gera um token no formato JWT (claims legíveis, assinatura aleatória)
+// O servidor fake valida tokens pela sessão em memória, não pela assinatura
+func newToken(u *user, appCode string, expiresAt time.Time) string {
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	header := encode(map[string]string{"alg": "none", "typ": "JWT"})
+	claims := encode(map[string]interface{}{
+		"sub":              u.ID,
+		"email":            u.Email,
+		"application_code": appCode,
+		"exp":              expiresAt.Unix(),
+	})
+	return header + "." + claims + "." + strings.ReplaceAll(newID(), "-", "")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,368 @@
+package fakeserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Ação usada nas permissions vinculadas a uma role na resposta do sync
+const actionLinked = "linked"
+
+// application é o estado em memória de uma aplicação
+type application struct {
+	ID              string
+	Code            string
+	Name            string
+	Description     string
+	Permissions     map[string]*client.PermissionState // por code
+	PermissionOrder []string
+	Roles           map[string]*client.RoleState // por code, com permissions expandidas
+	RoleOrder       []string
+}
+
+// user é o estado em memória de um usuário (global, vinculado a aplicações)
+type user struct {
+	ID       string
+	Email    string
+	Name     string
+	Password string // Em texto claro: servidor fake, apenas para desenvolvimento
+	Active   bool
+	TenantID *string
+	AppRoles map[string][]string // application code → roles
+}
+
+// syncPayload aceita o manifest completo ou o payload seletivo (com sections)
+type syncPayload struct {
+	Application manifest.Application  `json:"application"`
+	Permissions []manifest.Permission `json:"permissions"`
+	Roles       []manifest.Role       `json:"roles"`
+	Users       []syncUser            `json:"users"`
+	Sections    []string              `json:"sections"`
+
+	RemovedUsers []client.RemovedUser `json:"removed_users"`
+}
+
+// syncUser é o usuário do payload; active ausente (omitido no JSON) = ativo, o padrão do manifest
+// O CLI nunca envia active: false (manifest.User.Active é bool com omitempty); o campo só é
+// lido quando outro cliente da API o envia explicitamente
+type syncUser struct {
+	manifest.User
+	Active *bool `json:"active"`
+}
+
+// active informa o status pedido no payload
+func (u syncUser) active() bool {
+	return u.Active == nil || *u.Active
+}
+
+// newID gera um identificador aleatório no formato de UUID
+func newID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	h := hex.EncodeToString(buf)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// apply executa o sync conforme a ordem de processamento de docs/REGRAS_NEGOCIO.md
+// Itens com erro voltam com action "error" e não interrompem os demais
+// O chamador deve segurar s.mu
+func (s *Server) apply(p *syncPayload) (*client.SyncResponse, error) {
+	if strings.TrimSpace(p.Application.Code) == "" {
+		return nil, fmt.Errorf("application.code é obrigatório")
+	}
+	sections := p.Sections
+	if len(sections) == 0 {
+		sections = nil
+	}
+
+	resp := &client.SyncResponse{
+		Permissions: []client.SyncResultDTO{},
+		Roles:       []client.SyncRoleResultDTO{},
+	}
+
+	// 1. Aplicação (upsert por code)
+	app, ok := s.apps[p.Application.Code]
+	action := client.ActionUpdated
+	if !ok {
+		app = &application{
+			ID:          newID(),
+			Code:        p.Application.Code,
+			Permissions: map[string]*client.PermissionState{},
+			Roles:       map[string]*client.RoleState{},
+		}
+		s.apps[app.Code] = app
+		action = client.ActionCreated
+	}
+	app.Name = p.Application.Name
+	app.Description = p.Application.Description
+	resp.Application = client.SyncResultDTO{Code: app.Code, Action: action, ID: app.ID}
+
+	// 2. Permissões (upsert por application + code)
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		for _, perm := range p.Permissions {
+			resp.Permissions = append(resp.Permissions, app.upsertPermission(perm))
+		}
+	}
+
+	// 3 e 4. Roles (upsert por application + code) e Role-Permissions (regenerado)
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		for _, r := range p.Roles {
+			resp.Roles = append(resp.Roles, app.upsertRole(r))
+		}
+	}
+
+	// 5, 6 e 7. Usuários (upsert por email), vínculo com a aplicação e User-Roles
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		for _, u := range p.Users {
+			resp.Users = append(resp.Users, s.upsertUser(app, u))
+		}
+	}
+
+	// 8. Usuários fora do manifest (sync --users=authoritative)
+	for _, r := range p.RemovedUsers {
+		resp.RemovedUsers = append(resp.RemovedUsers, s.removeUser(app, r))
+	}
+
+	return resp, nil
+}
+
+// upsertPermission cria ou atualiza uma permission da aplicação
+func (a *application) upsertPermission(p manifest.Permission) client.SyncResultDTO {
+	if strings.TrimSpace(p.Code) == "" {
+		return client.SyncResultDTO{Code: p.Code, Action: client.ActionError, Message: "code é obrigatório"}
+	}
+	if manifest.IsWildcard(p.Code) {
+		return client.SyncResultDTO{Code: p.Code, Action: client.ActionError, Message: "wildcards são aceitos apenas nas roles"}
+	}
+
+	existing, ok := a.Permissions[p.Code]
+	action := client.ActionUpdated
+	if !ok {
+		existing = &client.PermissionState{ID: newID(), Code: p.Code}
+		a.Permissions[p.Code] = existing
+		a.PermissionOrder = append(a.PermissionOrder, p.Code)
+		action = client.ActionCreated
+	}
+	existing.Subject = p.Subject
+	existing.Action = p.Action
+	existing.Description = p.Description
+	existing.Conditions = p.Conditions
+	return client.SyncResultDTO{Code: p.Code, Action: action, ID: existing.ID}
+}
+
+// upsertRole cria ou atualiza uma role e regenera suas permissions
+// Wildcards são expandidos contra as permissions já cadastradas na aplicação;
+// a role master não pode ter permissions (o acesso total é concedido automaticamente)
+func (a *application) upsertRole(r manifest.Role) client.SyncRoleResultDTO {
+	result := client.SyncRoleResultDTO{Code: r.Code, Permissions: []client.SyncResultDTO{}}
+	if strings.TrimSpace(r.Code) == "" {
+		result.Action = client.ActionError
+		result.Message = "code é obrigatório"
+		return result
+	}
+	if manifest.IsMasterRole(r.Code) && len(r.Permissions) > 0 {
+		result.Action = client.ActionError
+		result.Message = "role master deve ter permissions vazio - o acesso total é concedido automaticamente"
+		return result
+	}
+
+	expanded, err := a.expand(r.Permissions)
+	if err != nil {
+		result.Action = client.ActionError
+		result.Message = err.Error()
+		return result
+	}
+
+	existing, ok := a.Roles[r.Code]
+	result.Action = client.ActionUpdated
+	if !ok {
+		existing = &client.RoleState{ID: newID(), Code: r.Code}
+		a.Roles[r.Code] = existing
+		a.RoleOrder = append(a.RoleOrder, r.Code)
+		result.Action = client.ActionCreated
+	}
+	existing.Name = r.Name
+	existing.Description = r.Description
+	existing.System = r.System
+	existing.Permissions = expanded
+	result.ID = existing.ID
+
+	for _, code := range expanded {
+		result.Permissions = append(result.Permissions, client.SyncResultDTO{Code: code, Action: actionLinked, ID: a.Permissions[code].ID})
+	}
+	return result
+}
+
+// expand resolve as referências de uma role (codes exatos e wildcards) em codes de permissions
+// Code exato inexistente é erro; wildcard que não casa com nada é ignorado
+func (a *application) expand(refs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, ref := range refs {
+		if !manifest.IsWildcard(ref) {
+			if _, ok := a.Permissions[ref]; !ok {
+				return nil, fmt.Errorf("permission %q não existe na aplicação %s", ref, a.Code)
+			}
+			if !seen[ref] {
+				seen[ref] = true
+				codes = append(codes, ref)
+			}
+			continue
+		}
+		for _, code := range a.PermissionOrder {
+			if !seen[code] && manifest.MatchesPermission(ref, code) {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	if codes == nil {
+		codes = []string{}
+	}
+	return codes, nil
+}
+
+// upsertUser cria ou atualiza um usuário, vincula à aplicação e atualiza suas roles nela
+// tenant_id só é aplicado na criação (não atualiza usuários existentes)
+func (s *Server) upsertUser(app *application, u syncUser) client.SyncResultDTO {
+	email := strings.ToLower(strings.TrimSpace(u.Email))
+	if email == "" {
+		return client.SyncResultDTO{Code: u.Email, Action: client.ActionError, Message: "email é obrigatório"}
+	}
+	for _, code := range u.Roles {
+		if _, ok := app.Roles[code]; !ok {
+			return client.SyncResultDTO{Code: u.Email, Action: client.ActionError, Message: fmt.Sprintf("role %q não existe na aplicação %s", code, app.Code)}
+		}
+	}
+
+	existing, ok := s.users[email]
+	action := client.ActionUpdated
+	if !ok {
+		if u.Password == "" {
+			return client.SyncResultDTO{Code: u.Email, Action: client.ActionError, Message: "password é obrigatório na criação do usuário"}
+		}
+		existing = &user{ID: newID(), Email: email, TenantID: u.TenantID, AppRoles: map[string][]string{}}
+		s.users[email] = existing
+		action = client.ActionCreated
+	}
+	existing.Name = u.Name
+	if u.Password != "" {
+		existing.Password = u.Password
+	}
+	existing.Active = u.active()
+	existing.AppRoles[app.Code] = append([]string{}, u.Roles...)
+	return client.SyncResultDTO{Code: u.Email, Action: action, ID: existing.ID}
+}
+
+// removeUser desativa um usuário vinculado à aplicação ou remove o vínculo (e as roles nela)
+func (s *Server) removeUser(app *application, r client.RemovedUser) client.SyncResultDTO {
+	email := strings.ToLower(strings.TrimSpace(r.Email))
+	existing, ok := s.users[email]
+	if !ok {
+		return client.SyncResultDTO{Code: r.Email, Action: client.ActionError, Message: "usuário não encontrado"}
+	}
+	if _, linked := existing.AppRoles[app.Code]; !linked {
+		return client.SyncResultDTO{Code: r.Email, Action: client.ActionError, Message: fmt.Sprintf("usuário não está vinculado à aplicação %s", app.Code)}
+	}
+
+	switch r.Action {
+	case client.RemoveDeactivate:
+		existing.Active = false
+		return client.SyncResultDTO{Code: r.Email, Action: client.ActionDeactivated, ID: existing.ID}
+	case client.RemoveUnlink:
+		delete(existing.AppRoles, app.Code)
+		return client.SyncResultDTO{Code: r.Email, Action: client.ActionUnlinked, ID: existing.ID}
+	}
+	return client.SyncResultDTO{Code: r.Email, Action: client.ActionError, Message: fmt.Sprintf("ação desconhecida %q (válidas: deactivate, unlink)", r.Action)}
+}
+
+// state monta a resposta de GET /v1/applications/{code}
+// O chamador deve segurar s.mu
+func (s *Server) state(code string) (*client.ApplicationState, bool) {
+	app, ok := s.apps[code]
+	if !ok {
+		return nil, false
+	}
+
+	state := &client.ApplicationState{
+		Permissions: []client.PermissionState{},
+		Roles:       []client.RoleState{},
+		Users:       []client.UserState{},
+	}
+	state.Application.ID = app.ID
+	state.Application.Code = app.Code
+	state.Application.Name = app.Name
+	state.Application.Description = app.Description
+
+	for _, code := range app.PermissionOrder {
+		state.Permissions = append(state.Permissions, *app.Permissions[code])
+	}
+	for _, code := range app.RoleOrder {
+		r := *app.Roles[code]
+		r.Permissions = append([]string{}, r.Permissions...)
+		state.Roles = append(state.Roles, r)
+	}
+
+	emails := make([]string, 0, len(s.users))
+	for email, u := range s.users {
+		if _, linked := u.AppRoles[code]; linked {
+			emails = append(emails, email)
+		}
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		u := s.users[email]
+		state.Users = append(state.Users, client.UserState{
+			ID:       u.ID,
+			Email:    u.Email,
+			Name:     u.Name,
+			Active:   u.Active,
+			TenantID: u.TenantID,
+			Roles:    append([]string{}, u.AppRoles[code]...),
+		})
+	}
+	return state, true
+}
+
+// Ability é uma regra CASL.js ({action, subject}) retornada por /me
+type Ability struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+}
+
+// abilities calcula as regras CASL.js do usuário na aplicação
+// A role master concede {action: "manage", subject: "all"}
+// O chamador deve segurar s.mu
+func (s *Server) abilities(u *user, appCode string) []Ability {
+	app, ok := s.apps[appCode]
+	if !ok {
+		return []Ability{}
+	}
+
+	seen := make(map[Ability]bool)
+	abilities := []Ability{}
+	for _, roleCode := range u.AppRoles[appCode] {
+		if manifest.IsMasterRole(roleCode) {
+			return []Ability{{Action: "manage", Subject: "all"}}
+		}
+		r, ok := app.Roles[roleCode]
+		if !ok {
+			continue
+		}
+		for _, code := range r.Permissions {
+			perm := app.Permissions[code]
+			ability := Ability{Action: perm.Action, Subject: perm.Subject}
+			if !seen[ability] {
+				seen[ability] = true
+				abilities = append(abilities, ability)
+			}
+		}
+	}
+	return abilities
+}
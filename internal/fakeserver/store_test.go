@@ -0,0 +1,38 @@
+package fakeserver
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// O CLI não envia active: false; o teste cobre o campo enviado por outros clientes da API
+func TestUpsertUserActiveField(t *testing.T) {
+	s := New(Options{Secret: "secret"})
+	sync := func(users string) {
+		t.Helper()
+		var p syncPayload
+		body := `{"application":{"code":"app","name":"App"},"roles":[{"code":"app.viewer","name":"Viewer"}],"users":` + users + `}`
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.apply(&p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		users string
+		want  bool
+	}{
+		{"ausente = ativo", `[{"email":"u@sagep.com.br","password":"x","roles":["app.viewer"]}]`, true},
+		{"active false", `[{"email":"u@sagep.com.br","active":false,"roles":["app.viewer"]}]`, false},
+		{"active true", `[{"email":"u@sagep.com.br","active":true,"roles":["app.viewer"]}]`, true},
+	}
+	for _, tc := range cases {
+		sync(tc.users)
+		if got := s.users["u@sagep.com.br"].Active; got != tc.want {
+			t.Errorf("%s: active = %t, esperava %t", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package graph monta o grafo de autorização dos manifests
+// (users → roles → permissions → subjects) para exportar em DOT ou Mermaid
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Tipos de nó
+const (
+	KindUser       = "user"
+	KindRole       = "role"
+	KindPermission = "permission"
+	KindSubject    = "subject"
+)
+
+// SubjectAll é o subject concedido pela role master (CASL.js: manage all)
+const SubjectAll = "all"
+
+// Filter restringe o grafo aos caminhos que passam pelos itens informados
+// Valores do mesmo filtro se somam; filtros diferentes precisam ser atendidos juntos
+// (ex: --role biopass.admin --subject biopass.devices = o que biopass.admin faz em biopass.devices)
+type Filter struct {
+	Roles    []string // Codes de roles
+	Subjects []string // Subjects (aceita wildcard, ex: biopass.*)
+	Users    []string // Emails (sem diferenciar maiúsculas)
+}
+
+// Node é um nó do grafo
+type Node struct {
+	ID    string
+	Kind  string
+	Label string
+}
+
+// Edge é uma aresta do grafo; Label indica o wildcard (ou manage) que concedeu o acesso
+type Edge struct {
+	From     string
+	To       string
+	Label    string
+	Wildcard bool
+}
+
+// Cluster é o grafo de uma aplicação
+type Cluster struct {
+	ID    string
+	Label string
+	Nodes []Node
+	Edges []Edge
+}
+
+// Graph agrupa os clusters de todos os manifests
+type Graph struct {
+	Clusters []Cluster
+}
+
+// Empty indica que nenhum caminho atendeu aos filtros
+func (g *Graph) Empty() bool {
+	return len(g.Clusters) == 0
+}
+
+// path é um caminho user → role → permission → subject; partes podem faltar
+// (role sem usuários, usuário sem roles, role master sem permissions)
+type path struct {
+	user, role, permission, subject string
+	label                           string
+	wildcard                        bool
+}
+
+// grant é uma permission concedida por uma role
+type grant struct {
+	permission, subject, label string
+	wildcard                   bool
+}
+
+// Build monta o grafo dos manifests, aplicando o filtro
+func Build(manifests []*manifest.AuthManifest, f Filter) *Graph {
+	g := &Graph{}
+	for i, m := range manifests {
+		var kept []path
+		for _, p := range paths(m) {
+			if f.matches(p) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		g.Clusters = append(g.Clusters, newCluster(fmt.Sprintf("a%d", i), m, kept))
+	}
+	return g
+}
+
+// paths enumera os caminhos do manifest, na ordem de declaração das roles
+func paths(m *manifest.AuthManifest) []path {
+	usersByRole := make(map[string][]string)
+	declared := make(map[string]bool, len(m.Roles))
+	for _, r := range m.Roles {
+		declared[r.Code] = true
+	}
+
+	var out []path
+	for _, u := range m.Users {
+		withRole := false
+		for _, role := range u.Roles {
+			if declared[role] {
+				usersByRole[role] = append(usersByRole[role], u.Email)
+				withRole = true
+			}
+		}
+		if !withRole {
+			out = append(out, path{user: u.Email})
+		}
+	}
+
+	for _, r := range m.Roles {
+		grants := roleGrants(m, r)
+		if len(grants) == 0 {
+			grants = []grant{{}}
+		}
+		users := usersByRole[r.Code]
+		if len(users) == 0 {
+			users = []string{""}
+		}
+		for _, gr := range grants {
+			for _, email := range users {
+				out = append(out, path{user: email, role: r.Code, permission: gr.permission, subject: gr.subject, label: gr.label, wildcard: gr.wildcard})
+			}
+		}
+	}
+	return out
+}
+
+// roleGrants lista as permissions concedidas pela role (wildcards expandidos)
+func roleGrants(m *manifest.AuthManifest, r manifest.Role) []grant {
+	if manifest.IsMasterRole(r.Code) {
+		return []grant{{subject: SubjectAll, label: "manage"}}
+	}
+
+	exact := make(map[string]bool, len(r.Permissions))
+	for _, ref := range r.Permissions {
+		exact[ref] = true
+	}
+	var grants []grant
+	for _, p := range m.Permissions {
+		if exact[p.Code] {
+			grants = append(grants, grant{permission: p.Code, subject: p.Subject})
+			continue
+		}
+		for _, ref := range r.Permissions {
+			if manifest.IsWildcard(ref) && manifest.MatchesPermission(ref, p.Code) {
+				grants = append(grants, grant{permission: p.Code, subject: p.Subject, label: ref, wildcard: true})
+				break
+			}
+		}
+	}
+	return grants
+}
+
+func (f Filter) matches(p path) bool {
+	if len(f.Users) > 0 && !containsFold(f.Users, p.user) {
+		return false
+	}
+	if len(f.Roles) > 0 && !contains(f.Roles, p.role) {
+		return false
+	}
+	if len(f.Subjects) > 0 {
+		if p.subject == "" {
+			return false
+		}
+		// A role master (manage all) aparece em qualquer filtro de subject
+		if p.subject != SubjectAll && !matchesAny(f.Subjects, p.subject) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, v string) bool {
+	for _, value := range values {
+		if v != "" && strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(refs []string, subject string) bool {
+	for _, ref := range refs {
+		if manifest.MatchesPermission(ref, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCluster converte os caminhos em nós e arestas sem repetição
+func newCluster(id string, m *manifest.AuthManifest, paths []path) Cluster {
+	c := Cluster{ID: id, Label: m.Application.Code}
+	if m.Application.Name != "" && m.Application.Name != m.Application.Code {
+		c.Label = fmt.Sprintf("%s (%s)", m.Application.Name, m.Application.Code)
+	}
+
+	nodes := make(map[string]string)
+	node := func(kind, key string) string {
+		if key == "" {
+			return ""
+		}
+		// Codes e subjects diferenciam maiúsculas (CASL.js compara exato); apenas emails não
+		k := kind + "\x00" + key
+		if kind == KindUser {
+			k = kind + "\x00" + strings.ToLower(key)
+		}
+		if nodeID, ok := nodes[k]; ok {
+			return nodeID
+		}
+		nodeID := fmt.Sprintf("%s_%s%d", id, kind[:1], len(c.Nodes))
+		nodes[k] = nodeID
+		c.Nodes = append(c.Nodes, Node{ID: nodeID, Kind: kind, Label: key})
+		return nodeID
+	}
+	edges := make(map[string]bool)
+	edge := func(e Edge) {
+		if e.From == "" || e.To == "" || edges[e.From+"\x00"+e.To] {
+			return
+		}
+		edges[e.From+"\x00"+e.To] = true
+		c.Edges = append(c.Edges, e)
+	}
+
+	for _, p := range paths {
+		user := node(KindUser, p.user)
+		role := node(KindRole, p.role)
+		permission := node(KindPermission, p.permission)
+		subject := node(KindSubject, p.subject)
+
+		edge(Edge{From: user, To: role})
+		if permission == "" {
+			edge(Edge{From: role, To: subject, Label: p.label})
+			continue
+		}
+		edge(Edge{From: role, To: permission, Label: p.label, Wildcard: p.wildcard})
+		edge(Edge{From: permission, To: subject})
+	}
+	return c
+}
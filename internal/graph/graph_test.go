@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+func TestBuildKeepsCodesThatDifferOnlyInCase(t *testing.T) {
+	m := &manifest.AuthManifest{
+		Application: manifest.Application{Code: "sagep-biopass"},
+		Permissions: []manifest.Permission{
+			{Code: "biopass.devices.read", Subject: "Device", Action: "read"},
+			{Code: "biopass.Devices.read", Subject: "device", Action: "read"},
+		},
+		Roles: []manifest.Role{
+			{Code: "biopass.viewer", Permissions: []string{"biopass.devices.read", "biopass.Devices.read"}},
+			{Code: "biopass.Viewer", Permissions: []string{"biopass.devices.read"}},
+		},
+		Users: []manifest.User{
+			{Email: "operador@sagep.com.br", Roles: []string{"biopass.viewer"}},
+			{Email: "Operador@sagep.com.br", Roles: []string{"biopass.Viewer"}},
+		},
+	}
+
+	g := Build([]*manifest.AuthManifest{m}, Filter{})
+	if len(g.Clusters) != 1 {
+		t.Fatalf("esperava 1 cluster, obteve %d", len(g.Clusters))
+	}
+	count := make(map[string]int)
+	for _, n := range g.Clusters[0].Nodes {
+		count[n.Kind]++
+	}
+	want := map[string]int{KindUser: 1, KindRole: 2, KindPermission: 2, KindSubject: 2}
+	for kind, n := range want {
+		if count[kind] != n {
+			t.Errorf("nós %s = %d, esperava %d (%+v)", kind, count[kind], n, g.Clusters[0].Nodes)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formatos de saída do comando graph
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Estilo de cada tipo de nó no DOT
+var dotStyles = map[string]string{
+	KindUser:       `shape=ellipse, style=filled, fillcolor="#ddf4ff"`,
+	KindRole:       `shape=box, style="rounded,filled", fillcolor="#fbefff"`,
+	KindPermission: `shape=note, style=filled, fillcolor="#f6f8fa"`,
+	KindSubject:    `shape=folder, style=filled, fillcolor="#dafbe1"`,
+}
+
+// WriteDOT escreve o grafo em Graphviz DOT, com um cluster por aplicação
+func WriteDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph sagep_auth {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=9];\n")
+	for _, c := range g.Clusters {
+		fmt.Fprintf(&b, "\n  subgraph cluster_%s {\n", c.ID)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(c.Label))
+		for _, n := range c.Nodes {
+			fmt.Fprintf(&b, "    %s [label=%s, %s];\n", n.ID, dotQuote(n.Label), dotStyles[n.Kind])
+		}
+		for _, e := range c.Edges {
+			var attrs []string
+			if e.Label != "" {
+				attrs = append(attrs, "label="+dotQuote(e.Label))
+			}
+			if e.Wildcard {
+				attrs = append(attrs, "style=dashed")
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(&b, "    %s -> %s [%s];\n", e.From, e.To, strings.Join(attrs, ", "))
+			} else {
+				fmt.Fprintf(&b, "    %s -> %s;\n", e.From, e.To)
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Formato de cada tipo de nó no Mermaid (abre e fecha o rótulo)
+var mermaidShapes = map[string][2]string{
+	KindUser:       {"([", "])"},
+	KindRole:       {"[", "]"},
+	KindPermission: {"[/", "/]"},
+	KindSubject:    {"[(", ")]"},
+}
+
+// WriteMermaid escreve o grafo como flowchart Mermaid (renderizado pelo GitLab/GitHub)
+// Com fenced, envolve o diagrama em um bloco ```mermaid para embutir em Markdown
+func WriteMermaid(w io.Writer, g *Graph, fenced bool) error {
+	var b strings.Builder
+	if fenced {
+		b.WriteString("```mermaid\n")
+	}
+	b.WriteString("flowchart LR\n")
+
+	classes := make(map[string][]string)
+	for _, c := range g.Clusters {
+		fmt.Fprintf(&b, "  subgraph %s[%s]\n", c.ID, mermaidQuote(c.Label))
+		for _, n := range c.Nodes {
+			shape := mermaidShapes[n.Kind]
+			fmt.Fprintf(&b, "    %s%s%s%s\n", n.ID, shape[0], mermaidQuote(n.Label), shape[1])
+			classes[n.Kind] = append(classes[n.Kind], n.ID)
+		}
+		for _, e := range c.Edges {
+			arrow := "-->"
+			if e.Wildcard {
+				arrow = "-.->"
+			}
+			if e.Label != "" {
+				fmt.Fprintf(&b, "    %s %s|%s| %s\n", e.From, arrow, mermaidQuote(e.Label), e.To)
+			} else {
+				fmt.Fprintf(&b, "    %s %s %s\n", e.From, arrow, e.To)
+			}
+		}
+		b.WriteString("  end\n")
+	}
+
+	b.WriteString("  classDef user fill:#ddf4ff,stroke:#54aeff\n")
+	b.WriteString("  classDef role fill:#fbefff,stroke:#c297ff\n")
+	b.WriteString("  classDef permission fill:#f6f8fa,stroke:#8c959f\n")
+	b.WriteString("  classDef subject fill:#dafbe1,stroke:#4ac26b\n")
+	for _, kind := range []string{KindUser, KindRole, KindPermission, KindSubject} {
+		if ids := classes[kind]; len(ids) > 0 {
+			fmt.Fprintf(&b, "  class %s %s\n", strings.Join(ids, ","), kind)
+		}
+	}
+	if fenced {
+		b.WriteString("```\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// mermaidQuote protege o rótulo (aspas viram a entidade #quot;)
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
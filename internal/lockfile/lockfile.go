@@ -0,0 +1,249 @@
+// Package lockfile implementa o auth-manifest.lock, que registra o último sync por ambiente
+//
+// O lock fica ao lado do manifest e é pensado para ser versionado: para cada ambiente
+// (perfil ou URL) e aplicação ele guarda o hash do manifest sincronizado, os IDs
+// retornados pelo servidor para cada code, o horário do sync e a versão do CLI.
+// Com ele o sync pula ambientes já em dia, o status mostra quais estão atrasados e
+// quem revisa uma mudança vê exatamente quais IDs serão alterados
+package lockfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/client"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName é o nome do lock, gravado no diretório do manifest
+const FileName = "auth-manifest.lock"
+
+// LockVersion é a versão do formato do lock
+const LockVersion = 1
+
+// Item é um permission, role ou usuário sincronizado
+type Item struct {
+	ID   string `yaml:"id"`
+	Hash string `yaml:"hash"` // Hash do item no manifest sincronizado (detecta alterações)
+}
+
+// Application é o último sync de uma aplicação em um ambiente
+type Application struct {
+	Manifest      string          `yaml:"manifest"`
+	ManifestHash  string          `yaml:"manifest_hash"`
+	Sections      []string        `yaml:"sections,omitempty"` // Vazio = todas as seções
+	SyncedAt      time.Time       `yaml:"synced_at"`
+	CLIVersion    string          `yaml:"cli_version"`
+	ApplicationID string          `yaml:"application_id"`
+	Permissions   map[string]Item `yaml:"permissions,omitempty"` // por code
+	Roles         map[string]Item `yaml:"roles,omitempty"`       // por code
+	Users         map[string]Item `yaml:"users,omitempty"`       // por email
+}
+
+// Environment é um servidor sagep-auth (perfil ou URL)
+type Environment struct {
+	URL          string                  `yaml:"url"`
+	Applications map[string]*Application `yaml:"applications"`
+}
+
+// Lock é o conteúdo do auth-manifest.lock
+type Lock struct {
+	LockVersion  int                     `yaml:"lock_version"`
+	Environments map[string]*Environment `yaml:"environments"`
+}
+
+// Path retorna o caminho do lock de um manifest
+func Path(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), FileName)
+}
+
+// EnvironmentKey identifica o ambiente no lock: o nome do perfil ou,
+// sem perfil (default), a URL do servidor
+func EnvironmentKey(profile, url string) string {
+	if profile != "" && profile != "default" {
+		return profile
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// Load carrega o lock
+// Retorna um lock vazio (sem erro) se o arquivo não existir
+func Load(path string) (*Lock, error) {
+	l := &Lock{LockVersion: LockVersion, Environments: map[string]*Environment{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse de %s: %w", path, err)
+	}
+	if l.LockVersion > LockVersion {
+		return nil, fmt.Errorf("%s: lock_version %d não suportado (atualize o CLI)", path, l.LockVersion)
+	}
+	if l.Environments == nil {
+		l.Environments = map[string]*Environment{}
+	}
+	return l, nil
+}
+
+// Save grava o lock (YAML com chaves ordenadas, para diffs estáveis)
+func (l *Lock) Save(path string) error {
+	l.LockVersion = LockVersion
+	var sb strings.Builder
+	sb.WriteString("# Gerado pelo sagep-auth-cli sync: não edite manualmente\n")
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return fmt.Errorf("erro ao serializar lock: %w", err)
+	}
+	encoder.Close()
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get retorna o último sync de uma aplicação em um ambiente (nil se nunca sincronizada)
+func (l *Lock) Get(env, appCode string) *Application {
+	e, ok := l.Environments[env]
+	if !ok {
+		return nil
+	}
+	return e.Applications[appCode]
+}
+
+// Record registra o sync de uma aplicação em um ambiente
+func (l *Lock) Record(env, url string, app *manifest.AuthManifest, entry *Application) {
+	e, ok := l.Environments[env]
+	if !ok {
+		e = &Environment{Applications: map[string]*Application{}}
+		l.Environments[env] = e
+	}
+	if e.Applications == nil {
+		e.Applications = map[string]*Application{}
+	}
+	e.URL = strings.TrimRight(url, "/")
+	e.Applications[app.Application.Code] = entry
+}
+
+// UpToDate indica se o último sync já enviou este manifest com as seções pedidas
+// Um sync completo cobre qualquer sync seletivo; um seletivo só cobre as mesmas seções
+func (a *Application) UpToDate(manifestHash string, sections []string) bool {
+	if a == nil || a.ManifestHash != manifestHash {
+		return false
+	}
+	if len(a.Sections) == 0 {
+		return true
+	}
+	return strings.Join(a.Sections, ",") == strings.Join(sections, ",")
+}
+
+// NewApplication monta a entrada do lock a partir da resposta do sync
+// Itens de seções não enviadas (sync seletivo) são mantidos do sync anterior
+func NewApplication(path string, m *manifest.AuthManifest, sections []string, resp *client.SyncResponse, previous *Application, cliVersion string) *Application {
+	entry := &Application{
+		Manifest:      filepath.ToSlash(path),
+		ManifestHash:  m.Hash(),
+		Sections:      sections,
+		SyncedAt:      time.Now().UTC().Truncate(time.Second),
+		CLIVersion:    cliVersion,
+		ApplicationID: resp.Application.ID,
+	}
+	if previous == nil {
+		previous = &Application{}
+	}
+
+	entry.Permissions = previous.Permissions
+	if manifest.HasSection(sections, manifest.SectionPermissions) {
+		hashes := PermissionHashes(m)
+		entry.Permissions = map[string]Item{}
+		for _, r := range resp.Permissions {
+			if h, ok := hashes[r.Code]; ok && r.ID != "" {
+				entry.Permissions[r.Code] = Item{ID: r.ID, Hash: h}
+			}
+		}
+	}
+
+	entry.Roles = previous.Roles
+	if manifest.HasSection(sections, manifest.SectionRoles) {
+		hashes := RoleHashes(m)
+		entry.Roles = map[string]Item{}
+		for _, r := range resp.Roles {
+			if h, ok := hashes[r.Code]; ok && r.ID != "" {
+				entry.Roles[r.Code] = Item{ID: r.ID, Hash: h}
+			}
+		}
+	}
+
+	entry.Users = previous.Users
+	if manifest.HasSection(sections, manifest.SectionUsers) {
+		hashes := UserHashes(m)
+		entry.Users = map[string]Item{}
+		for _, r := range resp.Users {
+			if h, ok := hashes[strings.ToLower(r.Code)]; ok && r.ID != "" {
+				entry.Users[strings.ToLower(r.Code)] = Item{ID: r.ID, Hash: h}
+			}
+		}
+	}
+	return entry
+}
+
+// PermissionHashes retorna o hash de cada permission do manifest, por code
+func PermissionHashes(m *manifest.AuthManifest) map[string]string {
+	hashes := make(map[string]string, len(m.Permissions))
+	for _, p := range m.Permissions {
+		hashes[p.Code] = itemHash(p)
+	}
+	return hashes
+}
+
+// RoleHashes retorna o hash de cada role do manifest, por code
+func RoleHashes(m *manifest.AuthManifest) map[string]string {
+	hashes := make(map[string]string, len(m.Roles))
+	for _, r := range m.Roles {
+		hashes[r.Code] = itemHash(r)
+	}
+	return hashes
+}
+
+// UserHashes retorna o hash de cada usuário do manifest, por email (minúsculo)
+// A senha não entra no hash: o lock é versionado e não deve permitir ataques de dicionário
+func UserHashes(m *manifest.AuthManifest) map[string]string {
+	hashes := make(map[string]string, len(m.Users))
+	for _, u := range m.Users {
+		u.Password = ""
+		hashes[strings.ToLower(u.Email)] = itemHash(u)
+	}
+	return hashes
+}
+
+// SortedKeys retorna as chaves de um mapa de itens em ordem alfabética
+func SortedKeys(items map[string]Item) []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// itemHash é o SHA-256 (16 primeiros dígitos hex) da serialização JSON do item
+func itemHash(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,125 @@
+package lockfile
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// PasswordState registra, fora do lock versionado, as senhas enviadas no último sync
+//
+// As senhas não entram no hash do lock (um SHA-256 de senha fraca é fácil de reverter),
+// então a troca de uma senha é detectada por este estado local: um HMAC das senhas de
+// cada aplicação, com uma chave aleatória que nunca sai da máquina. Sem o estado (CI,
+// outra máquina), não há como saber se uma senha mudou e os usuários são reenviados
+type PasswordState struct {
+	Key          string                       `json:"key"`          // Chave HMAC (base64)
+	Environments map[string]map[string]string `json:"environments"` // ambiente → aplicação → HMAC das senhas
+}
+
+// LoadPasswordState carrega o estado local, gerando a chave se o arquivo não existir
+func LoadPasswordState(path string) (*PasswordState, error) {
+	s := &PasswordState{Environments: map[string]map[string]string{}}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("erro ao fazer parse de %s: %w", path, err)
+		}
+		if s.Environments == nil {
+			s.Environments = map[string]map[string]string{}
+		}
+	}
+	if key, err := base64.StdEncoding.DecodeString(s.Key); err != nil || len(key) < 32 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("erro ao gerar chave do estado de senhas: %w", err)
+		}
+		// Chave nova invalida os registros anteriores
+		s.Key = base64.StdEncoding.EncodeToString(key)
+		s.Environments = map[string]map[string]string{}
+	}
+	return s, nil
+}
+
+// Fingerprint retorna o HMAC das senhas dos usuários do manifest ("" se nenhum tem senha)
+func (s *PasswordState) Fingerprint(m *manifest.AuthManifest) string {
+	var entries []string
+	for _, u := range m.Users {
+		if u.Password != "" {
+			entries = append(entries, strings.ToLower(u.Email)+"\x00"+u.Password)
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	sort.Strings(entries)
+
+	key, _ := base64.StdEncoding.DecodeString(s.Key)
+	mac := hmac.New(sha256.New, key)
+	for _, e := range entries {
+		mac.Write([]byte(e + "\n"))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Unchanged indica se as senhas do manifest são as do último sync registrado no ambiente
+// Sem estado (nil) ou sem registro, apenas manifests sem senhas estão em dia
+func (s *PasswordState) Unchanged(env string, m *manifest.AuthManifest) bool {
+	if s == nil {
+		return !hasPasswords(m)
+	}
+	fp := s.Fingerprint(m)
+	return fp == "" || s.Environments[env][m.Application.Code] == fp
+}
+
+// Record registra as senhas enviadas no sync de uma aplicação em um ambiente
+func (s *PasswordState) Record(env string, m *manifest.AuthManifest) {
+	apps, ok := s.Environments[env]
+	if !ok {
+		apps = map[string]string{}
+		s.Environments[env] = apps
+	}
+	if fp := s.Fingerprint(m); fp != "" {
+		apps[m.Application.Code] = fp
+	} else {
+		delete(apps, m.Application.Code)
+	}
+}
+
+// Save grava o estado com permissão 0600 (diretório 0700)
+func (s *PasswordState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar estado de senhas: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+	// WriteFile não altera a permissão de um arquivo existente
+	return os.Chmod(path, 0o600)
+}
+
+func hasPasswords(m *manifest.AuthManifest) bool {
+	for _, u := range m.Users {
+		if u.Password != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Estilos de subject suportados pelo bloco conventions
+const (
+	SubjectStyleNamespaced = "namespaced" // biopass.participants
+	SubjectStyleBare       = "bare"       // participants
+	SubjectStylePascal     = "pascal"     // Participants
+)
+
+// Pluralidade esperada para o nome do recurso
+const (
+	PluralityPlural   = "plural"
+	PluralitySingular = "singular"
+	PluralityAny      = "any"
+)
+
+// DefaultCodePattern é o padrão de code usado por InferResourcePermission
+const DefaultCodePattern = "{app}.{resource}.{action}"
+
+// Conventions define o padrão de nomenclatura das permissions do manifest
+// Não é enviado ao servidor - usado apenas pelo CLI (init, lint)
+type Conventions struct {
+	SubjectStyle string `yaml:"subject_style,omitempty" json:"-"` // namespaced | bare | pascal
+	CodePattern  string `yaml:"code_pattern,omitempty" json:"-"`  // ex: "{app}.{resource}.{action}"
+	Plurality    string `yaml:"plurality,omitempty" json:"-"`     // plural | singular | any
+}
+
+// DefaultConventions retorna as convenções usadas quando o manifest não declara o bloco
+// Alinhadas com InferResourcePermission: subject com namespace, code {app}.{resource}.{action}
+func DefaultConventions() Conventions {
+	return Conventions{
+		SubjectStyle: SubjectStyleNamespaced,
+		CodePattern:  DefaultCodePattern,
+		Plurality:    PluralityAny,
+	}
+}
+
+// EffectiveConventions retorna as convenções do manifest com defaults aplicados
+func (m *AuthManifest) EffectiveConventions() Conventions {
+	c := DefaultConventions()
+	if m.Conventions == nil {
+		return c
+	}
+	if m.Conventions.SubjectStyle != "" {
+		c.SubjectStyle = strings.ToLower(strings.TrimSpace(m.Conventions.SubjectStyle))
+	}
+	if m.Conventions.CodePattern != "" {
+		c.CodePattern = strings.TrimSpace(m.Conventions.CodePattern)
+	}
+	if m.Conventions.Plurality != "" {
+		c.Plurality = strings.ToLower(strings.TrimSpace(m.Conventions.Plurality))
+	}
+	return c
+}
+
+// Validate verifica se os valores do bloco conventions são conhecidos
+func (c Conventions) Validate() error {
+	switch c.SubjectStyle {
+	case SubjectStyleNamespaced, SubjectStyleBare, SubjectStylePascal:
+	default:
+		return fmt.Errorf("conventions.subject_style deve ser namespaced, bare ou pascal (atual: %s)", c.SubjectStyle)
+	}
+	switch c.Plurality {
+	case PluralityPlural, PluralitySingular, PluralityAny:
+	default:
+		return fmt.Errorf("conventions.plurality deve ser plural, singular ou any (atual: %s)", c.Plurality)
+	}
+	if !strings.Contains(c.CodePattern, "{resource}") || !strings.Contains(c.CodePattern, "{action}") {
+		return fmt.Errorf("conventions.code_pattern deve conter {resource} e {action} (atual: %s)", c.CodePattern)
+	}
+	return nil
+}
+
+// ResourcePermission gera code e subject de uma permission de recurso seguindo as convenções
+// resource deve estar normalizado (minúsculo, palavras separadas por hífen)
+func (c Conventions) ResourcePermission(resource, action, appCode string) (code, subject string) {
+	resource = c.applyPlurality(resource)
+	appShort := extractAppShortCode(strings.ToLower(strings.TrimSpace(appCode)))
+
+	code = c.CodePattern
+	code = strings.ReplaceAll(code, "{app}", appShort)
+	code = strings.ReplaceAll(code, "{resource}", resource)
+	code = strings.ReplaceAll(code, "{action}", action)
+
+	switch c.SubjectStyle {
+	case SubjectStyleBare:
+		subject = resource
+	case SubjectStylePascal:
+		subject = toPascal(resource)
+	default:
+		subject = appShort + "." + resource
+	}
+	return code, subject
+}
+
+// applyPlurality ajusta o nome do recurso conforme a pluralidade configurada
+func (c Conventions) applyPlurality(resource string) string {
+	switch c.Plurality {
+	case PluralityPlural:
+		return pluralize(resource)
+	case PluralitySingular:
+		return singularize(resource)
+	}
+	return resource
+}
+
+// LintIssue descreve uma permission que não segue as convenções do manifest
+type LintIssue struct {
+	Index    int    // Índice em permissions
+	Code     string // Code atual da permission
+	Field    string // "subject" ou "code"
+	Current  string
+	Expected string
+}
+
+// String formata o problema para exibição
+func (i LintIssue) String() string {
+	return fmt.Sprintf("permissions[%d] (%s): %s %q deveria ser %q", i.Index, i.Code, i.Field, i.Current, i.Expected)
+}
+
+// Lint verifica todas as permissions de recurso contra as convenções do manifest
+// Permissions de menu (Menu:{Nome}) seguem formato próprio e são ignoradas
+// Se glossary não for nil, o recurso é resolvido pelo glossário (participantes → participants)
+func Lint(m *AuthManifest, glossary *Glossary) ([]LintIssue, error) {
+	conv := m.EffectiveConventions()
+	if err := conv.Validate(); err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	for i, perm := range m.Permissions {
+		if strings.HasPrefix(perm.Code, "Menu:") {
+			continue
+		}
+		resource := glossary.Canonical(resourceFromPermission(perm))
+		if resource == "" {
+			continue
+		}
+
+		expectedCode, expectedSubject := conv.ResourcePermission(resource, perm.Action, m.Application.Code)
+		if perm.Subject != expectedSubject {
+			issues = append(issues, LintIssue{Index: i, Code: perm.Code, Field: "subject", Current: perm.Subject, Expected: expectedSubject})
+		}
+		if perm.Code != expectedCode {
+			issues = append(issues, LintIssue{Index: i, Code: perm.Code, Field: "code", Current: perm.Code, Expected: expectedCode})
+		}
+	}
+	return issues, nil
+}
+
+// CodeCollisions separa as correções de code que gerariam codes duplicados
+// Uma correção colide se o code esperado já pertence a outra permission ou se duas
+// correções levam ao mesmo code; correções de subject nunca colidem
+func CodeCollisions(m *AuthManifest, issues []LintIssue) (fixes, collisions []LintIssue) {
+	final := make([]string, len(m.Permissions))
+	blocked := make(map[int]bool)
+	for {
+		for i, perm := range m.Permissions {
+			final[i] = perm.Code
+		}
+		for i, issue := range issues {
+			if issue.Field == "code" && !blocked[i] {
+				final[issue.Index] = issue.Expected
+			}
+		}
+		count := make(map[string]int, len(final))
+		for _, code := range final {
+			count[code]++
+		}
+
+		// Uma correção bloqueada mantém o code antigo, que pode colidir com outra: repetir até estabilizar
+		changed := false
+		for i, issue := range issues {
+			if issue.Field == "code" && !blocked[i] && count[issue.Expected] > 1 {
+				blocked[i] = true
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	for i, issue := range issues {
+		if blocked[i] {
+			collisions = append(collisions, issue)
+		} else {
+			fixes = append(fixes, issue)
+		}
+	}
+	return fixes, collisions
+}
+
+// resourceFromPermission extrai o nome normalizado do recurso a partir do subject
+// Ex: "biopass.participants" → "participants", "DeviceLocal" → "device-local"
+func resourceFromPermission(perm Permission) string {
+	subject := strings.TrimSpace(perm.Subject)
+	if idx := strings.LastIndex(subject, "."); idx >= 0 {
+		subject = subject[idx+1:]
+	}
+	if subject == "" {
+		// Fallback: penúltimo segmento do code ({app}.{resource}.{action})
+		parts := strings.Split(perm.Code, ".")
+		if len(parts) < 2 {
+			return ""
+		}
+		subject = parts[len(parts)-2]
+	}
+	return fromPascal(subject)
+}
+
+// toPascal converte "device-local" → "DeviceLocal"
+func toPascal(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }) {
+		b.WriteString(capitalizeFirst(part))
+	}
+	return b.String()
+}
+
+// fromPascal converte "DeviceLocal" → "device-local" (strings já minúsculas ficam inalteradas)
+func fromPascal(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '-' && runes[i-1] != '_' && !unicode.IsUpper(runes[i-1]) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.ReplaceAll(b.String(), "_", "-")
+}
+
+// isPlural verifica (heuristicamente) se a última palavra do recurso está no plural
+func isPlural(word string) bool {
+	return strings.HasSuffix(word, "s")
+}
+
+// pluralize aplica regras simples de plural (inglês e português) à última palavra
+func pluralize(resource string) string {
+	prefix, word := splitLastWord(resource)
+	if word == "" || isPlural(word) {
+		return resource
+	}
+	switch {
+	case strings.HasSuffix(word, "ão"):
+		word = strings.TrimSuffix(word, "ão") + "ões"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		word = strings.TrimSuffix(word, "y") + "ies"
+	case strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		word += "es"
+	default:
+		word += "s"
+	}
+	return prefix + word
+}
+
+// singularize aplica regras simples de singular (inglês e português) à última palavra
+func singularize(resource string) string {
+	prefix, word := splitLastWord(resource)
+	if word == "" || !isPlural(word) || strings.HasSuffix(word, "ss") {
+		return resource
+	}
+	switch {
+	case strings.HasSuffix(word, "ões"):
+		word = strings.TrimSuffix(word, "ões") + "ão"
+	case strings.HasSuffix(word, "ies"):
+		word = strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		word = strings.TrimSuffix(word, "es")
+	default:
+		word = strings.TrimSuffix(word, "s")
+	}
+	return prefix + word
+}
+
+// splitLastWord separa "device-locals" em ("device-", "locals")
+func splitLastWord(resource string) (prefix, word string) {
+	idx := strings.LastIndex(resource, "-")
+	if idx < 0 {
+		return "", resource
+	}
+	return resource[:idx+1], resource[idx+1:]
+}
@@ -0,0 +1,47 @@
+package manifest
+
+import "testing"
+
+func TestCodeCollisions(t *testing.T) {
+	m := &AuthManifest{Permissions: []Permission{
+		{Code: "biopass.device.read"},
+		{Code: "biopass.devices.read"},
+		{Code: "biopass.locals.list"},
+		{Code: "biopass.local.list"},
+		{Code: "biopass.users.read"},
+	}}
+	issues := []LintIssue{
+		{Index: 0, Field: "code", Current: "biopass.device.read", Expected: "biopass.devices.read"}, // já existe
+		{Index: 0, Field: "subject", Current: "biopass.device", Expected: "biopass.devices"},
+		{Index: 2, Field: "code", Current: "biopass.locals.list", Expected: "biopass.locals.read"}, // duas correções
+		{Index: 3, Field: "code", Current: "biopass.local.list", Expected: "biopass.locals.read"},
+		{Index: 4, Field: "code", Current: "biopass.users.read", Expected: "biopass.user.read"},
+	}
+
+	fixes, collisions := CodeCollisions(m, issues)
+
+	if len(collisions) != 3 {
+		t.Fatalf("esperava 3 colisões, obteve %d: %v", len(collisions), collisions)
+	}
+	for _, c := range collisions {
+		if c.Field != "code" {
+			t.Errorf("correção de subject não deveria colidir: %v", c)
+		}
+	}
+	if len(fixes) != 2 || fixes[0].Field != "subject" || fixes[1].Expected != "biopass.user.read" {
+		t.Errorf("correções aplicáveis inesperadas: %v", fixes)
+	}
+}
+
+func TestCodeCollisionsChainedRename(t *testing.T) {
+	// a→b enquanto b→c: os codes finais não se repetem
+	m := &AuthManifest{Permissions: []Permission{{Code: "a.x.read"}, {Code: "a.y.read"}}}
+	issues := []LintIssue{
+		{Index: 0, Field: "code", Current: "a.x.read", Expected: "a.y.read"},
+		{Index: 1, Field: "code", Current: "a.y.read", Expected: "a.z.read"},
+	}
+	fixes, collisions := CodeCollisions(m, issues)
+	if len(collisions) != 0 || len(fixes) != 2 {
+		t.Errorf("renomeação encadeada não deveria colidir: fixes=%v collisions=%v", fixes, collisions)
+	}
+}
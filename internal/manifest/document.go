@@ -0,0 +1,280 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/redact"
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// Document mantém o manifest como árvore YAML para edições que preservam
+// comentários e a ordem original dos campos (lint --fix, etc.)
+type Document struct {
+	root *yaml.Node
+}
+
+// LoadDocument lê o arquivo do manifest como árvore YAML
+func LoadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo manifest: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do YAML: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("manifest deve ser um mapa YAML")
+	}
+
+	return &Document{root: &root}, nil
+}
+
+// Bytes serializa a árvore YAML com indentação de 2 espaços
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, fmt.Errorf("erro ao escrever YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao escrever YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save grava a árvore YAML no arquivo, mantendo indentação de 2 espaços
+func (d *Document) Save(path string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	mode := os.FileMode(0644)
+	if err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return fmt.Errorf("erro ao salvar arquivo: %w", err)
+	}
+	return nil
+}
+
+// Encrypt criptografa os campos password e !sensitive em texto claro (ver internal/secrets)
+func (d *Document) Encrypt(recipients []secrets.Recipient) (int, error) {
+	return secrets.EncryptTree(d.root, recipients)
+}
+
+// Decrypt substitui os valores ENC[...] pelo texto claro
+func (d *Document) Decrypt(identities []secrets.Identity) (int, error) {
+	return secrets.DecryptTree(d.root, identities)
+}
+
+// HasEncrypted indica se o documento contém valores ENC[...]
+func (d *Document) HasEncrypted() bool {
+	return secrets.HasEncrypted(d.root)
+}
+
+// EncryptedFields lista os valores ENC[...] do documento e seus destinatários
+func (d *Document) EncryptedFields() ([]secrets.EncryptedField, error) {
+	return secrets.EncryptedFields(d.root)
+}
+
+// Redact mascara senhas, tokens, secrets e campos !sensitive (ver internal/redact)
+// hash = true troca os valores por um hash curto em vez de ***
+func (d *Document) Redact(hash bool) int {
+	return redact.Tree(d.root, hash)
+}
+
+// Manifest decodifica e valida o documento como em LoadManifest
+// Valores ENC[...] não são decifrados (use Decrypt antes, se necessário)
+func (d *Document) Manifest() (*AuthManifest, error) {
+	return decodeManifest(d.root)
+}
+
+// APIVersion retorna a apiVersion declarada no documento ("" se ausente)
+func (d *Document) APIVersion() string {
+	return APIVersionOf(d.root)
+}
+
+// Section retorna a sequência de itens de uma seção (permissions, roles, users)
+func (d *Document) Section(name string) []*yaml.Node {
+	node := mappingValue(d.root.Content[0], name)
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// SetPermissionField altera um campo escalar de permissions[index]
+func (d *Document) SetPermissionField(index int, field, value string) error {
+	items := d.Section("permissions")
+	if index < 0 || index >= len(items) {
+		return fmt.Errorf("permissions[%d] não encontrada no arquivo", index)
+	}
+	setMappingScalar(items[index], field, value)
+	return nil
+}
+
+// RenamePermissionReferences substitui referências a permissions nas roles (code antigo → novo)
+// As trocas são aplicadas em uma única passada, então renomeações encadeadas (a→b, b→c)
+// não se misturam; retorna quantas referências foram alteradas
+func (d *Document) RenamePermissionReferences(renames map[string]string) int {
+	renamed := 0
+	for _, role := range d.Section("roles") {
+		perms := mappingValue(role, "permissions")
+		if perms == nil || perms.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range perms.Content {
+			if newCode, ok := renames[item.Value]; ok && item.Kind == yaml.ScalarNode {
+				item.Value = newCode
+				renamed++
+			}
+		}
+	}
+	return renamed
+}
+
+// FindUser retorna o índice do usuário com o email (sem diferenciar maiúsculas) ou -1
+func (d *Document) FindUser(email string) int {
+	for i, item := range d.Section("users") {
+		if value := mappingValue(item, "email"); value != nil && strings.EqualFold(value.Value, email) {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddUser acrescenta um usuário ao final da seção users (criada se não existir)
+func (d *Document) AddUser(u User) error {
+	var item yaml.Node
+	if err := item.Encode(u); err != nil {
+		return fmt.Errorf("erro ao serializar usuário %s: %w", u.Email, err)
+	}
+	if u.Password == "" {
+		removeMappingKey(&item, "password")
+	}
+
+	root := d.root.Content[0]
+	users := mappingValue(root, "users")
+	if users == nil {
+		users = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "users"}, users)
+	} else if users.Kind != yaml.SequenceNode {
+		// users: (vazio) ou users: null
+		*users = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	users.Style = 0 // users: [] vira lista em bloco
+	users.Content = append(users.Content, &item)
+	return nil
+}
+
+// UpdateUser altera name, password, tenant_id e roles de users[index]
+// Campos vazios (e roles nil) mantêm o valor atual; retorna se algo mudou
+// password é comparado como texto: para não regravar um ENC[...] equivalente, quem chama
+// compara a senha decifrada e passa password vazio quando ela não mudou
+func (d *Document) UpdateUser(index int, u User) (bool, error) {
+	items := d.Section("users")
+	if index < 0 || index >= len(items) {
+		return false, fmt.Errorf("users[%d] não encontrado no arquivo", index)
+	}
+	item := items[index]
+
+	changed := false
+	setScalar := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if current := mappingValue(item, key); current != nil && current.Kind == yaml.ScalarNode && current.Value == value {
+			return
+		}
+		setMappingScalar(item, key, value)
+		changed = true
+	}
+	setScalar("name", u.Name)
+	setScalar("password", u.Password)
+	if u.TenantID != nil {
+		setScalar("tenant_id", *u.TenantID)
+	}
+
+	if u.Roles != nil {
+		var current []string
+		if node := mappingValue(item, "roles"); node != nil {
+			if err := node.Decode(&current); err != nil {
+				return false, fmt.Errorf("users[%d].roles: %w", index, err)
+			}
+		}
+		if strings.Join(current, "\n") != strings.Join(u.Roles, "\n") {
+			node := mappingValue(item, "roles")
+			if node == nil {
+				node = &yaml.Node{}
+				item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "roles"}, node)
+			}
+			// Roles mantidas reaproveitam o nó original (e seus comentários)
+			existing := make(map[string]*yaml.Node, len(node.Content))
+			for _, r := range node.Content {
+				existing[r.Value] = r
+			}
+			content := make([]*yaml.Node, 0, len(u.Roles))
+			for _, role := range u.Roles {
+				if r, ok := existing[role]; ok {
+					content = append(content, r)
+					continue
+				}
+				content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: role})
+			}
+			node.Kind, node.Tag, node.Content = yaml.SequenceNode, "!!seq", content
+			if len(content) > 0 {
+				node.Style = 0
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// mappingValue retorna o nó de valor de uma chave em um mapa YAML
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingScalar altera (ou adiciona) uma chave escalar em um mapa YAML
+func setMappingScalar(node *yaml.Node, key, value string) {
+	if existing := mappingValue(node, key); existing != nil {
+		existing.Kind = yaml.ScalarNode
+		existing.Tag = "!!str"
+		existing.Style = 0
+		existing.Value = value
+		return
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// removeMappingKey remove uma chave (e seu valor) de um mapa YAML
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
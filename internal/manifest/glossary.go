@@ -0,0 +1,115 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultGlossaryFile é o nome do glossário procurado ao lado do manifest
+const DefaultGlossaryFile = "glossary.yaml"
+
+// Glossary mapeia nomes de entidades em pt-BR para os códigos canônicos usados nas permissions
+// Exemplo de glossary.yaml:
+//
+//	entities:
+//	  participantes: participants
+//	  ocorrências: occurrences
+//	  locais de dispositivo: locals
+type Glossary struct {
+	Entities map[string]string `yaml:"entities"`
+
+	index map[string]string // slug do nome → slug do código canônico
+}
+
+// LoadGlossary lê um arquivo de glossário YAML
+func LoadGlossary(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler glossário: %w", err)
+	}
+
+	var g Glossary
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do glossário: %w", err)
+	}
+
+	g.index = make(map[string]string, len(g.Entities))
+	for name, code := range g.Entities {
+		key := Slugify(name)
+		value := Slugify(code)
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("glossário: entrada inválida %q: %q", name, code)
+		}
+		if existing, ok := g.index[key]; ok && existing != value {
+			return nil, fmt.Errorf("glossário: %q mapeado para %q e %q", name, existing, value)
+		}
+		g.index[key] = value
+	}
+
+	return &g, nil
+}
+
+// LoadGlossaryFor carrega o glossário informado ou, se path for vazio,
+// procura glossary.yaml no diretório do manifest. Retorna nil se não existir.
+func LoadGlossaryFor(path, manifestPath string) (*Glossary, error) {
+	if path != "" {
+		return LoadGlossary(path)
+	}
+
+	candidate := filepath.Join(filepath.Dir(manifestPath), DefaultGlossaryFile)
+	if _, err := os.Stat(candidate); err != nil {
+		return nil, nil
+	}
+	return LoadGlossary(candidate)
+}
+
+// Canonical retorna o código canônico de uma entidade
+// Ex: "Participantes" → "participants" (via glossário), "Ocorrências" → "ocorrencias" (sem glossário)
+// Um glossário nil apenas aplica o slug
+func (g *Glossary) Canonical(entity string) string {
+	slug := Slugify(entity)
+	if g == nil {
+		return slug
+	}
+	if code, ok := g.index[slug]; ok {
+		return code
+	}
+	return slug
+}
+
+// accentReplacer remove acentos comuns do português (e alguns de outras línguas latinas)
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// Slugify converte um texto em slug ASCII minúsculo separado por hífens
+// Ex: "Ocorrências" → "ocorrencias", "Locais de Dispositivo" → "locais-de-dispositivo"
+func Slugify(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(strings.TrimSpace(s)))
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range s {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(r)
+		default:
+			// Espaços, underscores, hífens e demais caracteres viram um único separador
+			pendingDash = true
+		}
+	}
+	return b.String()
+}
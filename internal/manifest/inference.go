@@ -27,11 +27,21 @@ func InferMenuPermission(menuName string) (code, subject, action string) {
 }
 
 // InferResourcePermission cria uma permission de recurso a partir de entidade e ação
-// Entrada: entidade="participantes", action="read", appCode="sagep-biopass"
+// Entrada: entidade="participants", action="read", appCode="sagep-biopass"
 // Saída: code="biopass.participants.read", subject="biopass.participants", action="read"
 // Subject inclui namespace da aplicação para evitar conflitos em sistemas multi-aplicação
+// A entidade é convertida em slug ASCII ("Ocorrências" → "ocorrencias"); para traduzir
+// nomes em pt-BR (participantes → participants) use InferResourcePermissionWithGlossary
 func InferResourcePermission(entidade, action, appCode string) (code, subject, actionOut string) {
-	entidade = strings.TrimSpace(strings.ToLower(entidade))
+	return InferResourcePermissionWithGlossary(entidade, action, appCode, nil)
+}
+
+// InferResourcePermissionWithGlossary funciona como InferResourcePermission, mas resolve
+// a entidade pelo glossário antes de gerar code e subject
+// Entrada: entidade="participantes" (glossário: participantes → participants)
+// Saída: code="biopass.participants.read", subject="biopass.participants", action="read"
+func InferResourcePermissionWithGlossary(entidade, action, appCode string, glossary *Glossary) (code, subject, actionOut string) {
+	entidade = glossary.Canonical(entidade)
 	action = strings.TrimSpace(strings.ToLower(action))
 	appCode = strings.TrimSpace(strings.ToLower(appCode))
 	
@@ -83,15 +93,15 @@ func extractAppShortCode(appCode string) string {
 
 // InferApplicationCode gera código da aplicação a partir do nome
 // Entrada: "Biopass" → Saída: "sagep-biopass"
+// Entrada: "Gestão Prisional" → Saída: "sagep-gestao-prisional"
 func InferApplicationCode(appName string) string {
 	appName = strings.TrimSpace(appName)
 	if len(appName) == 0 {
 		return ""
 	}
 	
-	// Converter para minúsculo e substituir espaços por hífens
-	slug := strings.ToLower(appName)
-	slug = strings.ReplaceAll(slug, " ", "-")
+	// Converter para slug ASCII (minúsculo, sem acentos, espaços viram hífens)
+	slug := Slugify(appName)
 	
 	// Se não começa com "sagep-", adicionar
 	if !strings.HasPrefix(slug, "sagep-") {
@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+const encryptedManifest = `apiVersion: sagep-auth/v1
+application:
+  code: sagep-biopass
+  name: Biopass
+permissions:
+  - code: biopass.devices.read
+    subject: biopass.devices
+    action: read
+roles:
+  - code: biopass.viewer
+    name: Visualizador
+    permissions: [biopass.devices.read]
+users:
+  - email: operador@sagep.com.br
+    password: Biometria#Segura2026
+    name: Operador
+    roles: [biopass.viewer]
+`
+
+// writeEncryptedManifest grava o manifest com a senha cifrada para uma chave que não fica disponível
+func writeEncryptedManifest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("SAGEP_AUTH_KEY", "")
+	t.Setenv("SAGEP_AUTH_KEY_FILE", "")
+
+	key, err := secrets.GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(encryptedManifest), &root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secrets.EncryptTree(&root, []secrets.Recipient{key.Recipient()}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "auth-manifest.yaml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifestWithoutKeys(t *testing.T) {
+	path := writeEncryptedManifest(t)
+
+	if _, err := LoadManifest(path); !errors.Is(err, secrets.ErrNoKey) {
+		t.Fatalf("LoadManifest deve exigir a chave, obteve %v", err)
+	}
+
+	m, err := LoadManifestWithoutKeys(path)
+	if err != nil {
+		t.Fatalf("sem a chave, o manifest deve carregar com a senha cifrada: %v", err)
+	}
+	if !secrets.IsEncrypted(m.Users[0].Password) {
+		t.Fatalf("a senha deveria continuar cifrada: %q", m.Users[0].Password)
+	}
+	if issues := CheckPasswords(m); len(issues) != 0 {
+		t.Fatalf("senha cifrada não deve ser verificada pela política: %v", issues)
+	}
+}
@@ -1,10 +1,14 @@
 package manifest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
 	"gopkg.in/yaml.v3"
 )
 
@@ -54,6 +58,9 @@ type User struct {
 
 // AuthManifest representa o manifest completo
 type AuthManifest struct {
+	APIVersion string `yaml:"apiVersion,omitempty" json:"-"` // Versão do formato (ver version.go; apenas CLI)
+	Conventions *Conventions  `yaml:"conventions,omitempty" json:"-"` // Opcional: padrão de nomenclatura (apenas CLI)
+	PasswordPolicy *PasswordPolicy `yaml:"password_policy,omitempty" json:"-"` // Opcional: requisitos das senhas (apenas CLI)
 	Application Application  `yaml:"application" json:"application"`
 	Permissions []Permission  `yaml:"permissions" json:"permissions"`
 	Roles       []Role        `yaml:"roles" json:"roles"`
@@ -61,23 +68,46 @@ type AuthManifest struct {
 }
 
 // LoadManifest lê e valida um arquivo de manifest YAML
+// Valores ENC[...] são decifrados com as chaves locais (ver 'encrypt'); sem a chave, é erro
 func LoadManifest(path string) (*AuthManifest, error) {
+	return loadManifest(path, true)
+}
+
+// LoadManifestWithoutKeys lê e valida o manifest sem exigir as chaves: valores ENC[...]
+// que nenhuma chave local decifra ficam cifrados. Para comandos que não enviam nem
+// verificam senhas (matrix, graph, lint, status), que precisam funcionar para auditores
+func LoadManifestWithoutKeys(path string) (*AuthManifest, error) {
+	return loadManifest(path, false)
+}
+
+func loadManifest(path string, requireKeys bool) (*AuthManifest, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao ler arquivo manifest: %w", err)
 	}
 
-	var manifest AuthManifest
-	if err := yaml.Unmarshal(data, &manifest); err != nil {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("erro ao fazer parse do YAML: %w", err)
 	}
 
-	// Validações
-	if err := validateManifest(&manifest); err != nil {
-		return nil, err
+	if secrets.HasEncrypted(&root) {
+		identities, err := secrets.LoadIdentities(nil)
+		if err != nil {
+			return nil, err
+		}
+		if requireKeys {
+			_, err = secrets.DecryptTree(&root, identities)
+		} else {
+			_, err = secrets.DecryptAvailable(&root, identities)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao decifrar manifest: %w", err)
+		}
 	}
 
-	return &manifest, nil
+	// Decodificação e validações conforme a apiVersion
+	return decodeManifest(&root)
 }
 
 // validateManifest valida o conteúdo do manifest
@@ -90,6 +120,20 @@ func validateManifest(m *AuthManifest) error {
 		return fmt.Errorf("application.name não pode ser vazio")
 	}
 
+	// Validar conventions (se declarado)
+	if m.Conventions != nil {
+		if err := m.EffectiveConventions().Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validar password_policy (se declarado)
+	if m.PasswordPolicy != nil {
+		if err := m.EffectivePasswordPolicy().Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validar permissions
 	for i, perm := range m.Permissions {
 		if perm.Code == "" {
@@ -142,3 +186,24 @@ func validateManifest(m *AuthManifest) error {
 	return nil
 }
 
+
+// Hash retorna o SHA-256 (hex) da serialização JSON canônica do manifest
+// É o mesmo conteúdo enviado no sync completo, então manifests equivalentes
+// (mesmo com comentários ou formatação diferentes no YAML) têm o mesmo hash
+// As senhas ficam fora: o hash vai para o auth-manifest.lock versionado, bundles e a
+// saída json do sync, e o SHA-256 de uma senha fraca é fácil de reverter; a troca de
+// senhas é detectada pelo estado local do sync (lockfile.PasswordState)
+func (m *AuthManifest) Hash() string {
+	clone := *m
+	clone.Users = make([]User, len(m.Users))
+	for i, u := range m.Users {
+		u.Password = ""
+		clone.Users[i] = u
+	}
+	data, err := json.Marshal(&clone)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
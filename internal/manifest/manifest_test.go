@@ -0,0 +1,21 @@
+package manifest
+
+import "testing"
+
+func TestHashDoesNotExposePasswords(t *testing.T) {
+	newManifest := func(password string) *AuthManifest {
+		return &AuthManifest{
+			Application: Application{Code: "sagep-biopass", Name: "Biopass"},
+			Users:       []User{{Email: "operador@sagep.com.br", Password: password, Name: "Operador", Roles: []string{"biopass.viewer"}}},
+		}
+	}
+
+	// O hash vai para o lock versionado: não pode depender da senha (dicionário)
+	base := newManifest("Senha#Antiga2025")
+	if base.Hash() != newManifest("").Hash() {
+		t.Error("o hash não deve depender da senha")
+	}
+	if base.Users[0].Password != "Senha#Antiga2025" {
+		t.Error("Hash não pode alterar o manifest")
+	}
+}
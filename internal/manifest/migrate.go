@@ -0,0 +1,259 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MigrateOptions contém as opções da migração do manifest
+type MigrateOptions struct {
+	To          string // Versão de destino (padrão: CurrentAPIVersion)
+	RenameRoles bool   // Converte codes de role do formato antigo (BIOPASS_ADMIN → biopass.admin)
+}
+
+// MigrationNote registra uma decisão automática da migração (ou um item que exige revisão)
+type MigrationNote struct {
+	Item    string // Ex: permissions[0] (biopass.devices.read)
+	Message string
+	Manual  bool // A migração não conseguiu decidir: editar o manifest manualmente
+}
+
+// String formata a nota para exibição
+func (n MigrationNote) String() string {
+	return fmt.Sprintf("%s: %s", n.Item, n.Message)
+}
+
+// migration atualiza o documento de uma versão para a seguinte
+type migration struct {
+	from, to string
+	apply    func(d *Document, opts MigrateOptions) ([]MigrationNote, error)
+}
+
+// migrations em ordem de versão; uma mudança futura do schema acrescenta um passo
+// aqui (e um loader em version.go), e 'migrate' encadeia os passos até a versão pedida
+var migrations = []migration{
+	{from: APIVersionLegacy, to: APIVersionV1, apply: migrateLegacyToV1},
+}
+
+// legacyRoleCodePattern reconhece codes de role do formato antigo (BIOPASS_ADMIN)
+var legacyRoleCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)+$`)
+
+// Migrate atualiza o documento até opts.To (padrão: versão atual), preservando comentários
+// Retorna a versão de origem e as decisões tomadas em cada passo
+func (d *Document) Migrate(opts MigrateOptions) (string, []MigrationNote, error) {
+	to := opts.To
+	if to == "" {
+		to = CurrentAPIVersion
+	}
+	if _, ok := loaders[to]; !ok || to == APIVersionLegacy {
+		return "", nil, fmt.Errorf("apiVersion de destino inválida %q (suportadas: %s)", to, strings.Join(SupportedAPIVersions(), ", "))
+	}
+	from := d.APIVersion()
+	if _, ok := loaders[from]; !ok {
+		return "", nil, fmt.Errorf("apiVersion %q não suportada por esta versão do CLI; atualize o sagep-auth-cli", from)
+	}
+
+	var notes []MigrationNote
+	for version := from; version != to; {
+		step := findMigration(version)
+		if step == nil {
+			return from, nil, fmt.Errorf("não há migração de %s para %s", versionLabel(version), to)
+		}
+		stepNotes, err := step.apply(d, opts)
+		if err != nil {
+			return from, nil, fmt.Errorf("migração %s → %s: %w", versionLabel(step.from), step.to, err)
+		}
+		notes = append(notes, stepNotes...)
+		d.setAPIVersion(step.to)
+		version = step.to
+	}
+	return from, notes, nil
+}
+
+func findMigration(from string) *migration {
+	for i := range migrations {
+		if migrations[i].from == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// versionLabel nomeia a versão para mensagens ("" = sem apiVersion)
+func versionLabel(version string) string {
+	if version == APIVersionLegacy {
+		return "(sem apiVersion)"
+	}
+	return version
+}
+
+// migrateLegacyToV1 preenche subject/action das permissions com InferSubjectAndAction
+// (subject ajustado ao subject_style do manifest) e trata codes de role do formato antigo
+func migrateLegacyToV1(d *Document, opts MigrateOptions) ([]MigrationNote, error) {
+	root := d.root.Content[0]
+
+	var m AuthManifest
+	if node := mappingValue(root, "conventions"); node != nil {
+		if err := node.Decode(&m.Conventions); err != nil {
+			return nil, fmt.Errorf("conventions: %w", err)
+		}
+	}
+	conv := m.EffectiveConventions()
+	if err := conv.Validate(); err != nil {
+		return nil, err
+	}
+
+	var notes []MigrationNote
+	for i, item := range d.Section(SectionPermissions) {
+		code := scalarValue(mappingValue(item, "code"))
+		missingSubject := isEmptyScalar(mappingValue(item, "subject"))
+		missingAction := isEmptyScalar(mappingValue(item, "action"))
+		if !missingSubject && !missingAction {
+			continue
+		}
+		label := fmt.Sprintf("permissions[%d] (%s)", i, code)
+
+		subject, action, ok := InferSubjectAndAction(code)
+		if !ok {
+			notes = append(notes, MigrationNote{Item: label, Message: "não foi possível inferir subject/action do code (ações válidas: " + strings.Join(ValidActions, ", ") + "); preencha manualmente", Manual: true})
+			continue
+		}
+		subject, adjusted := conventionSubject(code, subject, conv)
+
+		var filled []string
+		after := "code"
+		if missingSubject {
+			insertMappingScalarAfter(item, after, "subject", subject)
+			filled = append(filled, fmt.Sprintf("subject %q", subject))
+			after = "subject"
+		}
+		if missingAction {
+			insertMappingScalarAfter(item, after, "action", action)
+			filled = append(filled, fmt.Sprintf("action %q", action))
+		}
+		message := strings.Join(filled, " e ") + " inferido(s) do code"
+		if missingSubject && adjusted {
+			message += fmt.Sprintf(" (subject_style %s)", conv.SubjectStyle)
+		}
+		notes = append(notes, MigrationNote{Item: label, Message: message})
+	}
+
+	appShort := extractAppShortCode(strings.ToLower(scalarValue(mappingValue(mappingValue(root, "application"), "code"))))
+	declared := make(map[string]bool)
+	for _, item := range d.Section(SectionRoles) {
+		declared[scalarValue(mappingValue(item, "code"))] = true
+	}
+	for i, item := range d.Section(SectionRoles) {
+		codeNode := mappingValue(item, "code")
+		code := scalarValue(codeNode)
+		if IsMasterRole(code) || !legacyRoleCodePattern.MatchString(code) {
+			continue
+		}
+		label := fmt.Sprintf("roles[%d] (%s)", i, code)
+		renamed := legacyRoleCode(code, appShort)
+
+		switch {
+		case !opts.RenameRoles:
+			notes = append(notes, MigrationNote{Item: label, Message: fmt.Sprintf("code no formato antigo mantido (use --rename-roles para converter em %q)", renamed)})
+		case declared[renamed]:
+			notes = append(notes, MigrationNote{Item: label, Message: fmt.Sprintf("não renomeada: a role %q já existe no manifest", renamed), Manual: true})
+		default:
+			codeNode.Value = renamed
+			declared[renamed] = true
+			users := d.renameRoleReferences(code, renamed)
+			notes = append(notes, MigrationNote{Item: label, Message: fmt.Sprintf("code renomeado para %q (%d usuário(s) atualizado(s)); o sync cria uma nova role e a antiga permanece no servidor", renamed, users)})
+		}
+	}
+	return notes, nil
+}
+
+// conventionSubject ajusta o subject inferido ao subject_style do manifest
+// Apenas codes no padrão {app}.{resource}.{action}; Menu:{Nome} e {Subject}.{action} ficam como inferidos
+// Retorna também se o subject_style foi aplicado
+func conventionSubject(code, inferred string, conv Conventions) (string, bool) {
+	parts := strings.Split(code, ".")
+	if strings.HasPrefix(code, "Menu:") || len(parts) < 3 {
+		return inferred, false
+	}
+	switch conv.SubjectStyle {
+	case SubjectStyleBare:
+		return inferred, true
+	case SubjectStylePascal:
+		return toPascal(fromPascal(inferred)), true
+	}
+	return strings.Join(parts[:len(parts)-1], "."), true
+}
+
+// legacyRoleCode converte BIOPASS_DEVICES_READONLY → biopass.devices-readonly
+// O prefixo só vira namespace quando coincide com o código curto da aplicação
+func legacyRoleCode(code, appShort string) string {
+	parts := strings.Split(strings.ToLower(code), "_")
+	if len(parts) > 1 && parts[0] == appShort {
+		return parts[0] + "." + strings.Join(parts[1:], "-")
+	}
+	return strings.Join(parts, "-")
+}
+
+// renameRoleReferences substitui a role em users[].roles; retorna quantos usuários mudaram
+func (d *Document) renameRoleReferences(oldCode, newCode string) int {
+	renamed := 0
+	for _, user := range d.Section(SectionUsers) {
+		roles := mappingValue(user, "roles")
+		if roles == nil || roles.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range roles.Content {
+			if item.Kind == yaml.ScalarNode && item.Value == oldCode {
+				item.Value = newCode
+				renamed++
+			}
+		}
+	}
+	return renamed
+}
+
+// setAPIVersion altera (ou insere no topo do documento) o campo apiVersion
+func (d *Document) setAPIVersion(version string) {
+	root := d.root.Content[0]
+	if node := mappingValue(root, "apiVersion"); node != nil {
+		node.Value = version
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "apiVersion"}
+	if len(root.Content) > 0 {
+		// O comentário de cabeçalho do arquivo continua no topo
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// insertMappingScalarAfter insere uma chave escalar logo após outra (ou no fim, se ausente)
+// Se a chave já existir (vazia), apenas altera o valor
+func insertMappingScalarAfter(node *yaml.Node, after, key, value string) {
+	if mappingValue(node, key) != nil {
+		setMappingScalar(node, key, value)
+		return
+	}
+	pair := []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == after {
+			node.Content = append(node.Content[:i+2], append(pair, node.Content[i+2:]...)...)
+			return
+		}
+	}
+	node.Content = append(node.Content, pair...)
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return strings.TrimSpace(node.Value)
+}
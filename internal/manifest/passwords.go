@@ -0,0 +1,198 @@
+package manifest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+)
+
+// Classes de caracteres aceitas em password_policy.require
+const (
+	PasswordClassUpper  = "upper"
+	PasswordClassLower  = "lower"
+	PasswordClassDigit  = "digit"
+	PasswordClassSymbol = "symbol"
+)
+
+// DefaultPasswordMinLength é o tamanho mínimo padrão das senhas dos usuários
+const DefaultPasswordMinLength = 12
+
+// GeneratedPasswordLength é o tamanho das senhas geradas por init --generate-passwords
+const GeneratedPasswordLength = 20
+
+// DefaultDeniedPasswords são senhas de exemplo (documentação, manifests de exemplo)
+// e senhas comuns que nunca devem chegar ao servidor
+var DefaultDeniedPasswords = []string{
+	"Master@123", "User@123", "Admin@123", "Senha@123", "Password@123",
+	"Sagep@123", "Mudar@123", "Trocar@123", "Teste@123",
+	"123456", "12345678", "123456789", "senha", "senha123", "password", "admin", "qwerty",
+}
+
+// PasswordPolicy define os requisitos das senhas dos usuários do manifest
+// Não é enviado ao servidor - usado apenas pelo CLI (validate, sync, init)
+type PasswordPolicy struct {
+	MinLength int      `yaml:"min_length,omitempty" json:"-"` // Padrão: DefaultPasswordMinLength
+	Require   []string `yaml:"require,omitempty" json:"-"`    // upper, lower, digit, symbol (padrão: todas)
+	Deny      []string `yaml:"deny,omitempty" json:"-"`       // Somadas a DefaultDeniedPasswords
+}
+
+// DefaultPasswordPolicy retorna a política usada quando o manifest não declara o bloco
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength: DefaultPasswordMinLength,
+		Require:   []string{PasswordClassUpper, PasswordClassLower, PasswordClassDigit, PasswordClassSymbol},
+	}
+}
+
+// EffectivePasswordPolicy retorna a política do manifest com defaults aplicados
+func (m *AuthManifest) EffectivePasswordPolicy() PasswordPolicy {
+	p := DefaultPasswordPolicy()
+	if m == nil || m.PasswordPolicy == nil {
+		return p
+	}
+	if m.PasswordPolicy.MinLength > 0 {
+		p.MinLength = m.PasswordPolicy.MinLength
+	}
+	if m.PasswordPolicy.Require != nil {
+		p.Require = make([]string, 0, len(m.PasswordPolicy.Require))
+		for _, class := range m.PasswordPolicy.Require {
+			p.Require = append(p.Require, strings.ToLower(strings.TrimSpace(class)))
+		}
+	}
+	p.Deny = m.PasswordPolicy.Deny
+	return p
+}
+
+// Validate verifica se os valores do bloco password_policy são conhecidos
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 8 {
+		return fmt.Errorf("password_policy.min_length deve ser pelo menos 8 (atual: %d)", p.MinLength)
+	}
+	for _, class := range p.Require {
+		switch class {
+		case PasswordClassUpper, PasswordClassLower, PasswordClassDigit, PasswordClassSymbol:
+		default:
+			return fmt.Errorf("password_policy.require aceita upper, lower, digit e symbol (atual: %s)", class)
+		}
+	}
+	return nil
+}
+
+// Check retorna os requisitos da política que a senha não atende
+// email é usado para rejeitar senhas que contêm o nome do usuário
+func (p PasswordPolicy) Check(password, email string) []string {
+	var problems []string
+	if len([]rune(password)) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("mínimo de %d caracteres", p.MinLength))
+	}
+
+	has := map[string]bool{}
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			has[PasswordClassUpper] = true
+		case unicode.IsLower(r):
+			has[PasswordClassLower] = true
+		case unicode.IsDigit(r):
+			has[PasswordClassDigit] = true
+		default:
+			has[PasswordClassSymbol] = true
+		}
+	}
+	names := map[string]string{
+		PasswordClassUpper:  "letra maiúscula",
+		PasswordClassLower:  "letra minúscula",
+		PasswordClassDigit:  "número",
+		PasswordClassSymbol: "símbolo",
+	}
+	for _, class := range p.Require {
+		if !has[class] {
+			problems = append(problems, "sem "+names[class])
+		}
+	}
+
+	for _, denied := range append(DefaultDeniedPasswords, p.Deny...) {
+		if strings.EqualFold(password, denied) {
+			problems = append(problems, "senha de exemplo ou muito comum")
+			break
+		}
+	}
+	if user, _, _ := strings.Cut(email, "@"); len(user) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(user)) {
+		problems = append(problems, "contém o nome do usuário do email")
+	}
+	return problems
+}
+
+// CheckPasswords verifica as senhas dos usuários contra a política do manifest
+// Usuários sem senha (atualização de usuários existentes) e senhas ainda cifradas
+// (sem a chave local, ver LoadManifestWithoutKeys) não são verificados
+func CheckPasswords(m *AuthManifest) []ReferenceIssue {
+	policy := m.EffectivePasswordPolicy()
+	var issues []ReferenceIssue
+	for i, u := range m.Users {
+		if u.Password == "" || secrets.IsEncrypted(u.Password) {
+			continue
+		}
+		if problems := policy.Check(u.Password, u.Email); len(problems) > 0 {
+			issues = append(issues, ReferenceIssue{Section: SectionUsers, Index: i, Item: u.Email, Message: "senha fraca: " + strings.Join(problems, ", ")})
+		}
+	}
+	return issues
+}
+
+// Alfabetos das senhas geradas (sem caracteres ambíguos como 0/O e 1/l/I)
+const (
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordDigits  = "23456789"
+	passwordSymbols = "!@#$%&*-_=+?"
+)
+
+// GeneratePassword gera uma senha aleatória (crypto/rand) que atende a política
+// Tem pelo menos GeneratedPasswordLength caracteres e um de cada classe
+func (p PasswordPolicy) GeneratePassword() (string, error) {
+	length := GeneratedPasswordLength
+	if p.MinLength > length {
+		length = p.MinLength
+	}
+
+	classes := []string{passwordUpper, passwordLower, passwordDigits, passwordSymbols}
+	all := strings.Join(classes, "")
+	password := make([]byte, 0, length)
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Embaralhar para as classes obrigatórias não ficarem sempre no início
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", fmt.Errorf("erro ao gerar senha: %w", err)
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+func randomChar(alphabet string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gerar senha: %w", err)
+	}
+	return alphabet[n.Int64()], nil
+}
@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AuxiliaryFiles são arquivos YAML do CLI que não são manifests
+// e devem ser ignorados ao expandir um diretório
+var AuxiliaryFiles = map[string]bool{
+	DefaultGlossaryFile: true,
+	DefaultTenantsFile:  true,
+}
+
+// ResolvePaths expande uma lista de arquivos, globs e diretórios em caminhos de manifests
+// - Arquivo: usado como está
+// - Glob (ex: "apps/*/auth-manifest.yaml"): expandido com filepath.Glob
+// - Diretório: todos os *.yaml / *.yml (exceto arquivos auxiliares como glossary.yaml)
+// O resultado é ordenado e sem duplicatas
+func ResolvePaths(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(p string) {
+		clean := filepath.Clean(p)
+		if !seen[clean] {
+			seen[clean] = true
+			paths = append(paths, clean)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("padrão inválido %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("nenhum manifest encontrado para %q", pattern)
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				add(match)
+			}
+			continue
+		}
+
+		info, err := os.Stat(pattern)
+		if err != nil || !info.IsDir() {
+			// Arquivo (ou inexistente - LoadManifest reporta o erro)
+			add(pattern)
+			continue
+		}
+
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler diretório %s: %w", pattern, err)
+		}
+		found := 0
+		for _, entry := range entries {
+			name := entry.Name()
+			ext := strings.ToLower(filepath.Ext(name))
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") || AuxiliaryFiles[name] {
+				continue
+			}
+			add(filepath.Join(pattern, name))
+			found++
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("nenhum manifest (*.yaml) encontrado em %s", pattern)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("nenhum manifest informado")
+	}
+	return paths, nil
+}
@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchesPermission verifica se uma referência de role (code exato ou wildcard) casa com um code
+// Ex: "biopass.*" casa com "biopass.devices.read"; "*" casa com qualquer permission
+func MatchesPermission(ref, code string) bool {
+	if ref == code {
+		return true
+	}
+	if !strings.ContainsAny(ref, "*?[") {
+		return false
+	}
+	matched, err := path.Match(ref, code)
+	return err == nil && matched
+}
+
+// IsWildcard indica se a referência é um wildcard (ex: "biopass.*")
+func IsWildcard(ref string) bool {
+	return strings.ContainsAny(ref, "*?[")
+}
+
+// IsMasterRole indica se o code é da role master (acesso total, sem permissions)
+func IsMasterRole(code string) bool {
+	return strings.ToLower(code) == "master"
+}
+
+// ReferenceIssue descreve uma referência que não resolve dentro do manifest
+type ReferenceIssue struct {
+	Section string // "roles" ou "users"
+	Index   int
+	Item    string // Code da role ou email do usuário
+	Message string
+}
+
+// String formata o problema para exibição
+func (i ReferenceIssue) String() string {
+	return fmt.Sprintf("%s[%d] (%s): %s", i.Section, i.Index, i.Item, i.Message)
+}
+
+// CheckReferences verifica se as roles referenciam permissions declaradas
+// (code exato ou wildcard que casa com ao menos uma) e se os usuários
+// referenciam roles declaradas no manifest
+func CheckReferences(m *AuthManifest) []ReferenceIssue {
+	var issues []ReferenceIssue
+
+	for i, role := range m.Roles {
+		for _, ref := range role.Permissions {
+			found := false
+			for _, perm := range m.Permissions {
+				if MatchesPermission(ref, perm.Code) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				msg := fmt.Sprintf("permission %q não declarada em permissions", ref)
+				if IsWildcard(ref) {
+					msg = fmt.Sprintf("wildcard %q não casa com nenhuma permission", ref)
+				}
+				issues = append(issues, ReferenceIssue{Section: "roles", Index: i, Item: role.Code, Message: msg})
+			}
+		}
+	}
+
+	roles := make(map[string]bool, len(m.Roles))
+	for _, role := range m.Roles {
+		roles[role.Code] = true
+	}
+	for i, user := range m.Users {
+		for _, roleCode := range user.Roles {
+			if !roles[roleCode] {
+				issues = append(issues, ReferenceIssue{Section: "users", Index: i, Item: user.Email, Message: fmt.Sprintf("role %q não declarada em roles", roleCode)})
+			}
+		}
+	}
+
+	return issues
+}
+
+// ExpandRolePermissions resolve as referências da role (codes exatos e wildcards)
+// nas permissions declaradas no manifest, na ordem de declaração e sem duplicatas
+// Referências que não casam com nenhuma permission são ignoradas (ver CheckReferences)
+func (m *AuthManifest) ExpandRolePermissions(role Role) []string {
+	codes := []string{}
+	for _, perm := range m.Permissions {
+		for _, ref := range role.Permissions {
+			if MatchesPermission(ref, perm.Code) {
+				codes = append(codes, perm.Code)
+				break
+			}
+		}
+	}
+	return codes
+}
@@ -0,0 +1,127 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Seções do manifest que podem ser sincronizadas seletivamente
+// A seção application é sempre enviada (identifica a aplicação)
+const (
+	SectionPermissions = "permissions"
+	SectionRoles       = "roles"
+	SectionUsers       = "users"
+)
+
+// AllSections lista as seções na ordem de processamento do servidor
+var AllSections = []string{SectionPermissions, SectionRoles, SectionUsers}
+
+// ResolveSections converte as listas de --only e --skip (separadas por vírgula)
+// nas seções a sincronizar. Retorna nil quando todas as seções devem ser enviadas
+func ResolveSections(only, skip string) ([]string, error) {
+	only = strings.TrimSpace(only)
+	skip = strings.TrimSpace(skip)
+	if only != "" && skip != "" {
+		return nil, fmt.Errorf("--only e --skip não podem ser usados juntos")
+	}
+	if only == "" && skip == "" {
+		return nil, nil
+	}
+
+	listed, err := parseSectionList(only + skip)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []string
+	for _, section := range AllSections {
+		if listed[section] == (only != "") {
+			sections = append(sections, section)
+		}
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("nenhuma seção para sincronizar (permissions, roles e users foram excluídas)")
+	}
+	if len(sections) == len(AllSections) {
+		return nil, nil
+	}
+	return sections, nil
+}
+
+// parseSectionList valida uma lista de seções separadas por vírgula
+func parseSectionList(list string) (map[string]bool, error) {
+	listed := make(map[string]bool)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if !containsSection(AllSections, item) {
+			return nil, fmt.Errorf("seção desconhecida %q (válidas: %s)", item, strings.Join(AllSections, ", "))
+		}
+		listed[item] = true
+	}
+	return listed, nil
+}
+
+func containsSection(sections []string, section string) bool {
+	for _, s := range sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSection indica se a seção será sincronizada (nil = todas)
+func HasSection(sections []string, section string) bool {
+	return sections == nil || containsSection(sections, section)
+}
+
+// CheckSectionDependencies verifica se as seções enviadas não dependem de itens omitidos
+//   - roles sem permissions: erro, pois Role-Permissions é regenerado a partir do manifest
+//     e permissions novas ainda não sincronizadas seriam descartadas pelo servidor
+//   - users sem roles: aviso, pois as roles são resolvidas pelo code no servidor
+//
+// Retorna os avisos (não bloqueantes) ou um erro
+func CheckSectionDependencies(m *AuthManifest, sections []string) ([]string, error) {
+	if sections == nil {
+		return nil, nil
+	}
+
+	var warnings []string
+
+	if HasSection(sections, SectionRoles) && !HasSection(sections, SectionPermissions) {
+		var problems []string
+		for i, role := range m.Roles {
+			if len(role.Permissions) > 0 {
+				problems = append(problems, fmt.Sprintf("roles[%d] (%s) referencia %d permission(s) não enviada(s)", i, role.Code, len(role.Permissions)))
+			}
+		}
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("roles dependem da seção permissions, que não será enviada:\n  %s\nInclua 'permissions' ou não envie 'roles'", strings.Join(problems, "\n  "))
+		}
+	}
+
+	if HasSection(sections, SectionUsers) && !HasSection(sections, SectionRoles) {
+		declared := make(map[string]bool, len(m.Roles))
+		for _, role := range m.Roles {
+			declared[role.Code] = true
+		}
+		seen := make(map[string]bool)
+		var used []string
+		for _, user := range m.Users {
+			for _, roleCode := range user.Roles {
+				if declared[roleCode] && !seen[roleCode] {
+					seen[roleCode] = true
+					used = append(used, roleCode)
+				}
+			}
+		}
+		if len(used) > 0 {
+			warnings = append(warnings, fmt.Sprintf("users referenciam roles não enviadas (%s) - o servidor usará a versão já sincronizada", strings.Join(used, ", ")))
+		}
+	}
+
+	return warnings, nil
+}
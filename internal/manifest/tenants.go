@@ -0,0 +1,198 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTenantsFile é o registro de tenants procurado ao lado do manifest
+const DefaultTenantsFile = "tenants.yaml"
+
+// Formas do tenant_id (ver docs/TENANT_ID_FORMAT.md)
+const (
+	TenantNone       = "none"       // Omitido: usuário global
+	TenantUnidade    = "unidade"    // UnidadeId (GUID)
+	TenantSecretaria = "secretaria" // SecretariaTenantId (slug, ex: sc-sejuc)
+	TenantInvalid    = "invalid"
+)
+
+// SecretariaRoles são as roles exigidas de usuários com tenant_id de secretaria
+var SecretariaRoles = []string{"master", "core_admin", "core_gestor_estrutura"}
+
+var (
+	guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// Valores só com hexadecimais e hífens (ou entre chaves) são tentativas de GUID
+	guidLikePattern = regexp.MustCompile(`^\{?[0-9a-fA-F-]{20,}\}?$`)
+)
+
+// TenantRegistry lista as unidades e secretarias conhecidas
+// Exemplo de tenants.yaml:
+//
+//	secretarias:
+//	  sc-sejuc: Secretaria de Justiça de SC
+//	unidades:
+//	  550e8400-e29b-41d4-a716-446655440000: Penitenciária de Florianópolis
+type TenantRegistry struct {
+	Secretarias map[string]string `yaml:"secretarias"`
+	Unidades    map[string]string `yaml:"unidades"`
+}
+
+// LoadTenantRegistry lê um arquivo tenants.yaml
+func LoadTenantRegistry(path string) (*TenantRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler registro de tenants: %w", err)
+	}
+
+	var r TenantRegistry
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do registro de tenants: %w", err)
+	}
+
+	// GUIDs são comparados sem diferenciar maiúsculas
+	unidades := make(map[string]string, len(r.Unidades))
+	for id, name := range r.Unidades {
+		if !guidPattern.MatchString(id) {
+			return nil, fmt.Errorf("%s: unidade %q não é um GUID válido", path, id)
+		}
+		unidades[strings.ToLower(id)] = name
+	}
+	r.Unidades = unidades
+	for slug := range r.Secretarias {
+		if !slugPattern.MatchString(slug) {
+			return nil, fmt.Errorf("%s: secretaria %q deve ser um slug (ex: sc-sejuc)", path, slug)
+		}
+	}
+	return &r, nil
+}
+
+// LoadTenantRegistryFor carrega o registro informado ou, se path for vazio,
+// procura tenants.yaml no diretório do manifest. Retorna nil se não existir.
+func LoadTenantRegistryFor(path, manifestPath string) (*TenantRegistry, error) {
+	if path != "" {
+		return LoadTenantRegistry(path)
+	}
+
+	candidate := filepath.Join(filepath.Dir(manifestPath), DefaultTenantsFile)
+	if _, err := os.Stat(candidate); err != nil {
+		return nil, nil
+	}
+	return LoadTenantRegistry(candidate)
+}
+
+// ClassifyTenantID identifica a forma do tenant_id
+// Para TenantInvalid, retorna também o motivo
+func ClassifyTenantID(tenantID *string) (string, string) {
+	if tenantID == nil || strings.TrimSpace(*tenantID) == "" {
+		return TenantNone, ""
+	}
+	id := *tenantID
+	switch {
+	case id != strings.TrimSpace(id):
+		return TenantInvalid, "tenant_id com espaços no início ou no fim"
+	case guidPattern.MatchString(id):
+		if strings.Trim(id, "0-") == "" {
+			return TenantInvalid, "tenant_id é o GUID nulo (00000000-...)"
+		}
+		return TenantUnidade, ""
+	case guidLikePattern.MatchString(id):
+		return TenantInvalid, fmt.Sprintf("GUID malformado %q (esperado xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)", id)
+	case slugPattern.MatchString(id):
+		return TenantSecretaria, ""
+	}
+	return TenantInvalid, fmt.Sprintf("tenant_id %q não é um GUID de unidade nem um slug de secretaria (ex: sc-sejuc)", id)
+}
+
+// CheckTenants classifica o tenant_id de cada usuário
+// Erros: GUID malformado, formato desconhecido, tenant fora do registro (quando há registro)
+// Avisos: usuário de secretaria sem master, core_admin ou core_gestor_estrutura
+func CheckTenants(m *AuthManifest, registry *TenantRegistry) (errs, warnings []ReferenceIssue) {
+	for i, u := range m.Users {
+		kind, problem := ClassifyTenantID(u.TenantID)
+		issue := func(msg string) ReferenceIssue {
+			return ReferenceIssue{Section: SectionUsers, Index: i, Item: u.Email, Message: msg}
+		}
+
+		switch kind {
+		case TenantInvalid:
+			errs = append(errs, issue(problem))
+		case TenantUnidade:
+			if registry != nil {
+				if _, ok := registry.Unidades[strings.ToLower(*u.TenantID)]; !ok {
+					errs = append(errs, issue(fmt.Sprintf("unidade %q não está em %s", *u.TenantID, DefaultTenantsFile)))
+				}
+			}
+		case TenantSecretaria:
+			if registry != nil {
+				if _, ok := registry.Secretarias[*u.TenantID]; !ok {
+					msg := fmt.Sprintf("secretaria %q não está em %s", *u.TenantID, DefaultTenantsFile)
+					if suggestion := registry.suggestSecretaria(*u.TenantID); suggestion != "" {
+						msg += fmt.Sprintf(" (quis dizer %q?)", suggestion)
+					}
+					errs = append(errs, issue(msg))
+				}
+			}
+			if !hasAnyRole(u.Roles, SecretariaRoles) {
+				warnings = append(warnings, issue(fmt.Sprintf("tenant_id de secretaria %q sem role %s", *u.TenantID, strings.Join(SecretariaRoles, ", "))))
+			}
+		}
+	}
+	return errs, warnings
+}
+
+func hasAnyRole(roles, wanted []string) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suggestSecretaria retorna a secretaria do registro mais parecida (até 2 edições)
+func (r *TenantRegistry) suggestSecretaria(slug string) string {
+	slugs := make([]string, 0, len(r.Secretarias))
+	for s := range r.Secretarias {
+		slugs = append(slugs, s)
+	}
+	sort.Strings(slugs)
+
+	best, bestDistance := "", 3
+	for _, s := range slugs {
+		if d := editDistance(slug, s); d < bestDistance {
+			best, bestDistance = s, d
+		}
+	}
+	return best
+}
+
+// editDistance calcula a distância de Levenshtein entre dois textos
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,97 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Versões do formato do manifest (campo apiVersion)
+const (
+	APIVersionLegacy  = ""              // Sem apiVersion: formato antigo (docs/prompts/auth_cli.md) ou v1 implícito
+	APIVersionV1      = "sagep-auth/v1" // subject/action obrigatórios, conventions, password_policy, users
+	CurrentAPIVersion = APIVersionV1
+)
+
+// loader decodifica e valida um manifest de uma versão específica
+type loader func(root *yaml.Node) (*AuthManifest, error)
+
+// loaders registra o decodificador de cada apiVersion suportada
+// Uma nova versão do schema ganha um loader aqui e uma migração em migrations
+var loaders = map[string]loader{
+	APIVersionLegacy: loadLegacy,
+	APIVersionV1:     loadV1,
+}
+
+// SupportedAPIVersions lista as versões declaráveis em apiVersion
+func SupportedAPIVersions() []string {
+	return []string{APIVersionV1}
+}
+
+// APIVersionOf lê o campo apiVersion da raiz do documento ("" se ausente)
+func APIVersionOf(root *yaml.Node) string {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if node := mappingValue(root, "apiVersion"); node != nil {
+		return strings.TrimSpace(node.Value)
+	}
+	return APIVersionLegacy
+}
+
+// decodeManifest escolhe o loader pela apiVersion do documento
+func decodeManifest(root *yaml.Node) (*AuthManifest, error) {
+	version := APIVersionOf(root)
+	load, ok := loaders[version]
+	if !ok {
+		return nil, fmt.Errorf("apiVersion %q não suportada por esta versão do CLI (suportadas: %s); atualize o sagep-auth-cli", version, strings.Join(SupportedAPIVersions(), ", "))
+	}
+	return load(root)
+}
+
+func loadV1(root *yaml.Node) (*AuthManifest, error) {
+	var manifest AuthManifest
+	if err := root.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("erro ao fazer parse do YAML: %w", err)
+	}
+	if err := validateManifest(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// loadLegacy aceita manifests sem apiVersion que já seguem o v1
+// Arquivos no formato antigo (sem subject/action) falham na validação com a dica do migrate
+func loadLegacy(root *yaml.Node) (*AuthManifest, error) {
+	manifest, err := loadV1(root)
+	if err != nil && NeedsMigration(root) {
+		return nil, fmt.Errorf("%w (manifest no formato antigo: execute 'sagep-auth-cli migrate')", err)
+	}
+	return manifest, err
+}
+
+// NeedsMigration indica se o documento está no formato antigo (sem apiVersion e
+// com permissions sem subject/action)
+func NeedsMigration(root *yaml.Node) bool {
+	if APIVersionOf(root) != APIVersionLegacy {
+		return false
+	}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	permissions := mappingValue(root, "permissions")
+	if permissions == nil {
+		return false
+	}
+	for _, item := range permissions.Content {
+		if isEmptyScalar(mappingValue(item, "subject")) || isEmptyScalar(mappingValue(item, "action")) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyScalar(node *yaml.Node) bool {
+	return node == nil || strings.TrimSpace(node.Value) == ""
+}
@@ -0,0 +1,161 @@
+// Package matrix monta a matriz roles × permissions ("quem pode fazer o quê")
+// e a visão users × roles de um manifest, para auditoria
+package matrix
+
+import (
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/manifest"
+)
+
+// Formas de acesso de uma role a uma permission (da mais para a menos explícita)
+const (
+	AccessNone     = ""
+	AccessDirect   = "direct"   // Code listado na role
+	AccessWildcard = "wildcard" // Coberta por um wildcard da role (ex: biopass.*)
+	AccessManage   = "manage"   // Coberta por manage no subject (ou role master: manage all)
+)
+
+// ManageAction é a ação CASL.js que concede todas as ações do subject
+const ManageAction = "manage"
+
+// SubjectAll é o subject CASL.js que representa todos os subjects
+const SubjectAll = "all"
+
+// Role é uma coluna da matriz
+type Role struct {
+	Code   string
+	Name   string
+	Master bool
+}
+
+// Row é uma permission e o acesso de cada role (Access[i] corresponde a Roles[i])
+type Row struct {
+	Code        string
+	Action      string
+	Description string
+	Access      []string
+}
+
+// Group agrupa as permissions de um subject
+type Group struct {
+	Subject string
+	Rows    []Row
+}
+
+// Matrix é a matriz roles × permissions de uma aplicação
+type Matrix struct {
+	Application string
+	Name        string
+	Roles       []Role
+	Groups      []Group
+}
+
+// UserRow é um usuário e as roles que possui (Has[i] corresponde a Roles[i])
+type UserRow struct {
+	Email string
+	Name  string
+	Has   []bool
+}
+
+// UserMatrix é a visão users × roles
+type UserMatrix struct {
+	Roles []Role
+	Users []UserRow
+}
+
+// Build monta a matriz, com subjects na ordem da primeira declaração
+func Build(m *manifest.AuthManifest) *Matrix {
+	mx := &Matrix{Application: m.Application.Code, Name: m.Application.Name, Roles: roles(m)}
+
+	access := make([]map[string]string, len(m.Roles))
+	for i, role := range m.Roles {
+		access[i] = roleAccess(m, role)
+	}
+
+	index := make(map[string]int)
+	for _, p := range m.Permissions {
+		row := Row{Code: p.Code, Action: p.Action, Description: p.Description, Access: make([]string, len(m.Roles))}
+		for i := range m.Roles {
+			row.Access[i] = access[i][p.Code]
+		}
+		g, ok := index[p.Subject]
+		if !ok {
+			g = len(mx.Groups)
+			index[p.Subject] = g
+			mx.Groups = append(mx.Groups, Group{Subject: p.Subject})
+		}
+		mx.Groups[g].Rows = append(mx.Groups[g].Rows, row)
+	}
+	return mx
+}
+
+// BuildUsers monta a visão users × roles, na ordem do manifest
+func BuildUsers(m *manifest.AuthManifest) *UserMatrix {
+	um := &UserMatrix{Roles: roles(m)}
+	for _, u := range m.Users {
+		has := make(map[string]bool, len(u.Roles))
+		for _, code := range u.Roles {
+			has[code] = true
+		}
+		row := UserRow{Email: u.Email, Name: u.Name, Has: make([]bool, len(m.Roles))}
+		for i, role := range m.Roles {
+			row.Has[i] = has[role.Code]
+		}
+		um.Users = append(um.Users, row)
+	}
+	return um
+}
+
+func roles(m *manifest.AuthManifest) []Role {
+	out := make([]Role, 0, len(m.Roles))
+	for _, r := range m.Roles {
+		out = append(out, Role{Code: r.Code, Name: r.Name, Master: manifest.IsMasterRole(r.Code)})
+	}
+	return out
+}
+
+// roleAccess calcula o acesso da role a cada permission do manifest (code → forma de acesso)
+// Ordem de precedência: direct, wildcard, manage
+func roleAccess(m *manifest.AuthManifest, role manifest.Role) map[string]string {
+	access := make(map[string]string, len(m.Permissions))
+	if manifest.IsMasterRole(role.Code) {
+		for _, p := range m.Permissions {
+			access[p.Code] = AccessManage
+		}
+		return access
+	}
+
+	refs := make(map[string]bool, len(role.Permissions))
+	for _, ref := range role.Permissions {
+		refs[ref] = true
+	}
+	managed := make(map[string]bool) // subjects com manage concedido
+	for _, p := range m.Permissions {
+		switch {
+		case refs[p.Code]:
+			access[p.Code] = AccessDirect
+		case matchesAny(role.Permissions, p.Code):
+			access[p.Code] = AccessWildcard
+		default:
+			continue
+		}
+		if p.Action == ManageAction {
+			managed[p.Subject] = true
+		}
+	}
+
+	for _, p := range m.Permissions {
+		if access[p.Code] == AccessNone && (managed[p.Subject] || managed[SubjectAll]) {
+			access[p.Code] = AccessManage
+		}
+	}
+	return access
+}
+
+func matchesAny(refs []string, code string) bool {
+	for _, ref := range refs {
+		if manifest.IsWildcard(ref) && manifest.MatchesPermission(ref, code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package matrix
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Formatos de saída do comando matrix
+const (
+	FormatCSV      = "csv"
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// Símbolos das células nos formatos para leitura humana (Markdown e HTML)
+var symbols = map[string]string{
+	AccessDirect:   "✔",
+	AccessWildcard: "✱",
+	AccessManage:   "⚙",
+}
+
+// legend descreve os símbolos das células
+const legend = "✔ direta · ✱ via wildcard · ⚙ via manage (ou role master)"
+
+// Tabelas do formato CSV (um arquivo por tabela)
+const (
+	TablePermissions = "permissions"
+	TableUsers       = "users"
+)
+
+// WriteCSV escreve a matriz em CSV (uma linha por permission, células direct/wildcard/manage)
+func WriteCSV(w io.Writer, mx *Matrix) error {
+	header := []string{"subject", "permission", "action", "description"}
+	for _, r := range mx.Roles {
+		header = append(header, r.Code)
+	}
+	records := [][]string{header}
+	for _, g := range mx.Groups {
+		for _, row := range g.Rows {
+			records = append(records, append([]string{g.Subject, row.Code, row.Action, row.Description}, row.Access...))
+		}
+	}
+	return writeCSV(w, records)
+}
+
+// WriteUsersCSV escreve a visão users × roles em CSV ("x" = usuário tem a role)
+func WriteUsersCSV(w io.Writer, users *UserMatrix) error {
+	header := []string{"email", "name"}
+	for _, r := range users.Roles {
+		header = append(header, r.Code)
+	}
+	records := [][]string{header}
+	for _, u := range users.Users {
+		record := []string{u.Email, u.Name}
+		for _, has := range u.Has {
+			record = append(record, mark(has, "x"))
+		}
+		records = append(records, record)
+	}
+	return writeCSV(w, records)
+}
+
+func writeCSV(w io.Writer, records [][]string) error {
+	cw := csv.NewWriter(w)
+	for _, record := range records {
+		for i := range record {
+			record[i] = csvCell(record[i])
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell evita que planilhas interpretem a célula como fórmula (CSV injection):
+// valores iniciados por = + - @ (ou tab/CR) recebem um apóstrofo na frente
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// WriteMarkdown escreve a matriz em Markdown, com uma tabela por subject
+func WriteMarkdown(w io.Writer, mx *Matrix, users *UserMatrix) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Matriz de permissões — %s\n\n", title(mx))
+	fmt.Fprintf(&b, "%s\n", legend)
+
+	roleHeader := func(roles []Role) (string, string) {
+		head, sep := "", ""
+		for _, r := range roles {
+			head += " " + escapeMarkdown(r.Code) + " |"
+			sep += ":---:|"
+		}
+		return head, sep
+	}
+
+	head, sep := roleHeader(mx.Roles)
+	for _, g := range mx.Groups {
+		fmt.Fprintf(&b, "\n## %s\n\n", escapeMarkdown(g.Subject))
+		fmt.Fprintf(&b, "| Permission | Ação |%s\n", head)
+		fmt.Fprintf(&b, "|---|---|%s\n", sep)
+		for _, row := range g.Rows {
+			fmt.Fprintf(&b, "| `%s` | %s |", escapeMarkdown(row.Code), escapeMarkdown(row.Action))
+			for _, access := range row.Access {
+				fmt.Fprintf(&b, " %s |", symbols[access])
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if users != nil {
+		head, sep := roleHeader(users.Roles)
+		b.WriteString("\n## Usuários × roles\n\n")
+		fmt.Fprintf(&b, "| Email | Nome |%s\n", head)
+		fmt.Fprintf(&b, "|---|---|%s\n", sep)
+		for _, u := range users.Users {
+			fmt.Fprintf(&b, "| %s | %s |", escapeMarkdown(u.Email), escapeMarkdown(u.Name))
+			for _, has := range u.Has {
+				fmt.Fprintf(&b, " %s |", mark(has, "✔"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML escreve a matriz como uma página HTML autocontida (CSS embutido, sem recursos externos)
+func WriteHTML(w io.Writer, mx *Matrix, users *UserMatrix) error {
+	return htmlTemplate.Execute(w, struct {
+		Title  string
+		Legend string
+		Matrix *Matrix
+		Users  *UserMatrix
+	}{title(mx), legend, mx, users})
+}
+
+func title(mx *Matrix) string {
+	if mx.Name != "" && mx.Name != mx.Application {
+		return fmt.Sprintf("%s (%s)", mx.Name, mx.Application)
+	}
+	return mx.Application
+}
+
+func mark(ok bool, symbol string) string {
+	if ok {
+		return symbol
+	}
+	return ""
+}
+
+// escapeMarkdown evita que | quebre as colunas da tabela (inclusive dentro de `code`)
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+var htmlTemplate = template.Must(template.New("matrix").Funcs(template.FuncMap{
+	"symbol": func(access string) string { return symbols[access] },
+	"span":   func(roles []Role) int { return len(roles) + 2 },
+}).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Matriz de permissões — {{.Title}}</title>
+<style>
+body { font-family: system-ui, -apple-system, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+h1 { font-size: 1.4rem; }
+h2 { font-size: 1.1rem; margin-top: 2.5rem; }
+p.legend { color: #57606a; }
+table { border-collapse: collapse; font-size: 0.85rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.6rem; }
+thead th { position: sticky; top: 0; background: #f6f8fa; }
+th.role { writing-mode: vertical-rl; transform: rotate(180deg); white-space: nowrap; }
+th.master { color: #8250df; }
+tr.subject th { background: #eaeef2; text-align: left; }
+td.cell { text-align: center; min-width: 1.5rem; }
+td.direct { background: #dafbe1; }
+td.wildcard { background: #fff8c5; }
+td.manage { background: #ddf4ff; }
+td.has { background: #dafbe1; }
+code { font-size: 0.85rem; }
+</style>
+</head>
+<body>
+<h1>Matriz de permissões — {{.Title}}</h1>
+<p class="legend">{{.Legend}}</p>
+<table>
+<thead>
+<tr><th>Permission</th><th>Ação</th>{{range .Matrix.Roles}}<th class="role{{if .Master}} master{{end}}" title="{{.Name}}">{{.Code}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- $roles := .Matrix.Roles}}
+{{- range .Matrix.Groups}}
+<tr class="subject"><th colspan="{{span $roles}}">{{.Subject}}</th></tr>
+{{- range .Rows}}
+<tr><td title="{{.Description}}"><code>{{.Code}}</code></td><td>{{.Action}}</td>{{range .Access}}<td class="cell {{.}}">{{symbol .}}</td>{{end}}</tr>
+{{- end}}
+{{- end}}
+</tbody>
+</table>
+{{- with .Users}}
+<h2>Usuários × roles</h2>
+<table>
+<thead>
+<tr><th>Email</th><th>Nome</th>{{range .Roles}}<th class="role{{if .Master}} master{{end}}" title="{{.Name}}">{{.Code}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Users}}
+<tr><td>{{.Email}}</td><td>{{.Name}}</td>{{range .Has}}<td class="cell{{if .}} has{{end}}">{{if .}}✔{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+</body>
+</html>
+`))
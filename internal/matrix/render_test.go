@@ -0,0 +1,91 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	mx := &Matrix{
+		Roles: []Role{{Code: "biopass.viewer"}},
+		Groups: []Group{{Subject: "=devices", Rows: []Row{
+			{Code: "biopass.devices.read", Action: "read", Description: "+SOMA(A1)", Access: []string{AccessDirect}},
+			{Code: "biopass.devices.delete", Action: "delete", Description: "-1", Access: []string{AccessNone}},
+			{Code: "biopass.devices.update", Action: "update", Description: "@HYPERLINK()", Access: []string{AccessNone}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, mx); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("esperava cabeçalho + 3 linhas, obteve %d", len(records))
+	}
+	for _, want := range []struct {
+		row, col int
+		value    string
+	}{
+		{1, 0, "'=devices"},
+		{1, 3, "'+SOMA(A1)"},
+		{2, 3, "'-1"},
+		{3, 3, "'@HYPERLINK()"},
+		{1, 1, "biopass.devices.read"},
+	} {
+		if got := records[want.row][want.col]; got != want.value {
+			t.Errorf("célula [%d][%d] = %q, esperava %q", want.row, want.col, got, want.value)
+		}
+	}
+}
+
+func TestWriteUsersCSVIsSeparateTable(t *testing.T) {
+	users := &UserMatrix{
+		Roles: []Role{{Code: "biopass.viewer"}, {Code: "biopass.admin"}},
+		Users: []UserRow{{Email: "operador@sagep.com.br", Name: "=Operador", Has: []bool{true, false}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteUsersCSV(&buf, users); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"email", "name", "biopass.viewer", "biopass.admin"},
+		{"operador@sagep.com.br", "'=Operador", "x", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("registros = %v, esperava %v", records, want)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("linha %d = %v, esperava %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestWriteMarkdownEscapesPipeInCode(t *testing.T) {
+	mx := &Matrix{
+		Application: "sagep-biopass",
+		Roles:       []Role{{Code: "biopass.viewer"}},
+		Groups: []Group{{Subject: "devices", Rows: []Row{
+			{Code: "biopass.devices|read", Action: "read", Access: []string{AccessDirect}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, mx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "| `biopass.devices\\|read` | read |") {
+		t.Errorf("o | do code deve ser escapado dentro da célula:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,183 @@
+// Package redact mascara senhas, tokens e secrets no que o CLI exibe: o manifest em
+// 'show' e as mensagens e bodies de erro devolvidos pelo servidor
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BrBit-Sistemas/sagep-auth-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// Mask substitui valores sensíveis na saída
+const Mask = "***"
+
+// sensitiveKeys são trechos de nomes de campos cujo valor nunca deve ser exibido
+// (comparação sem diferenciar maiúsculas; "-" equivale a "_")
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "private_key", "credential"}
+
+// minKnownLength evita mascarar valores curtos demais (ex: "a") em todo o texto
+const minKnownLength = 4
+
+// keyValuePattern encontra pares chave/valor em texto livre (JSON inválido, logs, form-urlencoded)
+var keyValuePattern = regexp.MustCompile(`(?i)("?[\w-]*(?:password|secret|token|authorization|api_?key|private_key|credential)[\w-]*"?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|[^\s,&}"]+)`)
+
+// IsSensitiveKey indica se o campo guarda senha, token ou secret
+func IsSensitiveKey(key string) bool {
+	k := strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value retorna o substituto exibido no lugar de um valor sensível
+// hash = true usa um hash curto (sha256:xxxxxxxxxxxx) para comparar valores sem revelá-los
+func Value(value string, hash bool) string {
+	if value == "" {
+		return ""
+	}
+	if !hash {
+		return Mask
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// Tree mascara os escalares de campos sensíveis e os marcados com !sensitive
+// Retorna quantos valores foram mascarados
+func Tree(node *yaml.Node, hash bool) int {
+	count := 0
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			count += Tree(child, hash)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if value.Kind == yaml.ScalarNode && (IsSensitiveKey(key) || value.Tag == secrets.SensitiveTag) {
+				if value.Value != "" {
+					value.Value = Value(value.Value, hash)
+					value.Tag = "!!str"
+					value.Style = 0
+					count++
+				}
+				continue
+			}
+			count += Tree(value, hash)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == secrets.SensitiveTag && node.Value != "" {
+			node.Value = Value(node.Value, hash)
+			node.Tag = "!!str"
+			count++
+		}
+	}
+	return count
+}
+
+// Values extrai os valores de campos sensíveis de um payload JSON
+// Usado para remover ecos do payload em respostas de erro do servidor
+func Values(payload []byte) []string {
+	var data interface{}
+	if len(payload) == 0 || json.Unmarshal(payload, &data) != nil {
+		return nil
+	}
+	var values []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if s, ok := child.(string); ok && IsSensitiveKey(key) {
+					values = append(values, s)
+					continue
+				}
+				collect(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				collect(child)
+			}
+		}
+	}
+	collect(data)
+	return values
+}
+
+// Text remove segredos de um texto livre (ex: body de erro do servidor)
+// JSON válido tem os campos sensíveis mascarados; em qualquer texto, pares chave=valor
+// sensíveis e os valores conhecidos (known: senhas do payload, secret, token) viram Mask
+func Text(s string, known ...string) string {
+	if s == "" {
+		return s
+	}
+
+	// Valores mais longos primeiro (um valor pode conter outro)
+	sorted := make([]string, 0, len(known))
+	for _, value := range known {
+		if len(value) >= minKnownLength {
+			sorted = append(sorted, value)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	// JSON: os valores conhecidos são mascarados nas strings decodificadas, pois no texto
+	// codificado eles podem aparecer escapados (ex: \u0026 ou \" no lugar de & e ")
+	var data interface{}
+	if json.Unmarshal([]byte(s), &data) == nil {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if encoder.Encode(maskJSON(data, sorted)) == nil {
+			s = strings.TrimSuffix(buf.String(), "\n")
+		}
+	} else {
+		s = keyValuePattern.ReplaceAllStringFunc(s, func(match string) string {
+			parts := keyValuePattern.FindStringSubmatch(match)
+			if strings.HasPrefix(parts[2], `"`) {
+				return parts[1] + `"` + Mask + `"`
+			}
+			return parts[1] + Mask
+		})
+	}
+	return maskKnown(s, sorted)
+}
+
+// maskKnown troca os valores conhecidos (ordenados do mais longo ao mais curto) por Mask
+func maskKnown(s string, sorted []string) string {
+	for _, value := range sorted {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	return s
+}
+
+// maskJSON mascara os campos sensíveis e os valores conhecidos de um valor JSON decodificado
+func maskJSON(v interface{}, known []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if s, ok := child.(string); ok && IsSensitiveKey(key) {
+				v[key] = Value(s, false)
+				continue
+			}
+			v[key] = maskJSON(child, known)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = maskJSON(child, known)
+		}
+	case string:
+		return maskKnown(v, known)
+	}
+	return v
+}
@@ -0,0 +1,92 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		known  []string
+		want   string
+		leaked []string
+	}{
+		{
+			name:   "JSON com campo sensível",
+			input:  `{"message":"erro","password":"Senha#Forte2026"}`,
+			want:   `{"message":"erro","password":"***"}`,
+			leaked: []string{"Senha#Forte2026"},
+		},
+		{
+			name:   "JSON com valor conhecido em outro campo",
+			input:  `{"message":"senha Senha#Forte2026 inválida"}`,
+			known:  []string{"Senha#Forte2026"},
+			want:   `{"message":"senha *** inválida"}`,
+			leaked: []string{"Senha#Forte2026"},
+		},
+		{
+			name:   "JSON com caracteres especiais no valor conhecido",
+			input:  `{"message":"senha A&b<c>d\"e inválida"}`,
+			known:  []string{`A&b<c>d"e`},
+			want:   `{"message":"senha *** inválida"}`,
+			leaked: []string{"A&b", `&`, `b<c`},
+		},
+		{
+			name:  "JSON sem escapar HTML",
+			input: `{"message":"a <b> & c"}`,
+			want:  `{"message":"a <b> & c"}`,
+		},
+		{
+			name:   "texto com par chave=valor",
+			input:  `erro ao criar usuário: password=Senha#Forte2026, email=x@sagep.com.br`,
+			want:   `erro ao criar usuário: password=***, email=x@sagep.com.br`,
+			leaked: []string{"Senha#Forte2026"},
+		},
+		{
+			name:   "texto com chave entre aspas",
+			input:  `payload inválido: {"secret": "s3cr3t-hmac" ...`,
+			want:   `payload inválido: {"secret": "***" ...`,
+			leaked: []string{"s3cr3t-hmac"},
+		},
+		{
+			name:  "texto com valor conhecido e caracteres especiais",
+			input: `token rejeitado: A&b<c>d"e`,
+			known: []string{`A&b<c>d"e`},
+			want:  `token rejeitado: ***`,
+		},
+		{
+			name:  "valor conhecido curto não é mascarado",
+			input: `{"message":"abc"}`,
+			known: []string{"abc"},
+			want:  `{"message":"abc"}`,
+		},
+		{
+			name:  "valor mais longo primeiro",
+			input: `senha Senha#Forte2026-extra`,
+			known: []string{"Senha#Forte2026", "Senha#Forte2026-extra"},
+			want:  `senha ***`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Text(tc.input, tc.known...)
+			if got != tc.want {
+				t.Errorf("Text() = %s, esperava %s", got, tc.want)
+			}
+			for _, leak := range tc.leaked {
+				if strings.Contains(got, leak) {
+					t.Errorf("saída contém %q: %s", leak, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	got := Values([]byte(`{"users":[{"email":"a@sagep.com.br","password":"Senha#Forte2026"}],"api_key":"k-123"}`))
+	if len(got) != 2 {
+		t.Fatalf("esperava 2 valores sensíveis, obteve %v", got)
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	File      string          `xml:"file,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit escreve o relatório no formato JUnit XML (GitLab, Jenkins)
+// Cada permission, role e usuário é um testcase com classname {app}.{seção}
+func WriteJUnit(w io.Writer, r *Report) error {
+	total, failed, skipped := r.Counts()
+	doc := junitTestSuites{
+		Name:     "sagep-auth-cli " + r.Command,
+		Tests:    total,
+		Failures: failed,
+		Skipped:  skipped,
+	}
+
+	for _, s := range r.Suites {
+		t, f, sk := s.Counts()
+		suite := junitTestSuite{
+			Name:      s.Name,
+			Tests:     t,
+			Failures:  f,
+			Skipped:   sk,
+			Time:      fmt.Sprintf("%.3f", s.Duration.Seconds()),
+			Timestamp: r.GeneratedAt.UTC().Format("2006-01-02T15:04:05"),
+			File:      s.File,
+		}
+		for _, c := range s.Cases {
+			tc := junitTestCase{
+				Name:      c.Name,
+				Classname: s.Name + "." + c.Section,
+			}
+			switch c.Status {
+			case StatusFailed:
+				tc.Failure = &junitMessage{Message: c.Message, Body: c.Message}
+			case StatusSkipped:
+				tc.Skipped = &junitMessage{Message: c.Message}
+			default:
+				if c.Action != "" {
+					tc.SystemOut = c.Action
+				}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
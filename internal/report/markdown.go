@@ -0,0 +1,107 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown escreve um resumo em Markdown (para comentários em merge requests)
+// Uma tabela por aplicação com contagens por seção, seguida dos itens com falha
+func WriteMarkdown(w io.Writer, r *Report) error {
+	var b strings.Builder
+
+	total, failed, skipped := r.Counts()
+	icon := "✅"
+	if failed > 0 {
+		icon = "❌"
+	}
+	fmt.Fprintf(&b, "## %s sagep-auth-cli %s\n\n", icon, r.Command)
+	fmt.Fprintf(&b, "%d item(ns), %d falha(s), %d ignorado(s) — %s\n", total, failed, skipped, r.GeneratedAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+	for _, s := range r.Suites {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Name)
+		if s.File != "" {
+			fmt.Fprintf(&b, "Manifest: `%s`\n\n", s.File)
+		}
+
+		withActions := false
+		for _, c := range s.Cases {
+			if c.Action != "" {
+				withActions = true
+				break
+			}
+		}
+
+		if withActions {
+			b.WriteString("| Seção | Criados | Atualizados | Falhas | Ignorados |\n")
+			b.WriteString("|---|---:|---:|---:|---:|\n")
+		} else {
+			b.WriteString("| Seção | OK | Falhas | Ignorados |\n")
+			b.WriteString("|---|---:|---:|---:|\n")
+		}
+		for _, section := range sectionOrder(s.Cases) {
+			var created, updated, passed, sectionFailed, sectionSkipped int
+			for _, c := range s.Cases {
+				if c.Section != section {
+					continue
+				}
+				switch c.Status {
+				case StatusFailed:
+					sectionFailed++
+				case StatusSkipped:
+					sectionSkipped++
+				default:
+					passed++
+					switch c.Action {
+					case "created":
+						created++
+					case "updated":
+						updated++
+					}
+				}
+			}
+			if withActions {
+				fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", section, created, updated, sectionFailed, sectionSkipped)
+			} else {
+				fmt.Fprintf(&b, "| %s | %d | %d | %d |\n", section, passed, sectionFailed, sectionSkipped)
+			}
+		}
+
+		var problems []Case
+		for _, c := range s.Cases {
+			if c.Status != StatusPassed {
+				problems = append(problems, c)
+			}
+		}
+		if len(problems) > 0 {
+			b.WriteString("\n| Seção | Item | Status | Mensagem |\n")
+			b.WriteString("|---|---|---|---|\n")
+			for _, c := range problems {
+				fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", c.Section, c.Name, c.Status, escapeCell(c.Message))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sectionOrder retorna as seções na ordem em que aparecem
+func sectionOrder(cases []Case) []string {
+	seen := make(map[string]bool)
+	var order []string
+	for _, c := range cases {
+		if !seen[c.Section] {
+			seen[c.Section] = true
+			order = append(order, c.Section)
+		}
+	}
+	return order
+}
+
+// escapeCell evita que a mensagem quebre a tabela Markdown
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status é o resultado de um item do relatório
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Case é um item do relatório (permission, role, user ou verificação do manifest)
+type Case struct {
+	Section string // Ex: "permissions", "roles", "users", "manifest"
+	Name    string // Code da permission/role ou email do usuário
+	Action  string // Ação informada pelo servidor (created, updated) - vazio fora do sync
+	Status  Status
+	Message string
+}
+
+// Suite agrupa os itens de um manifest (uma aplicação)
+type Suite struct {
+	Name     string // application.code
+	File     string // Caminho do manifest
+	Duration time.Duration
+	Cases    []Case
+}
+
+// Report é o relatório de um comando (sync, validate)
+type Report struct {
+	Command     string
+	GeneratedAt time.Time
+	Suites      []Suite
+}
+
+// Counts retorna o total de itens, falhas e ignorados do relatório
+func (r *Report) Counts() (total, failed, skipped int) {
+	for _, s := range r.Suites {
+		t, f, sk := s.Counts()
+		total += t
+		failed += f
+		skipped += sk
+	}
+	return total, failed, skipped
+}
+
+// Counts retorna o total de itens, falhas e ignorados da suite
+func (s *Suite) Counts() (total, failed, skipped int) {
+	for _, c := range s.Cases {
+		total++
+		switch c.Status {
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return total, failed, skipped
+}
+
+// WriteFile grava o relatório no formato indicado pela extensão do arquivo
+// .xml → JUnit, .md → Markdown
+func WriteFile(path string, r *Report) error {
+	var write func(*os.File, *Report) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		write = func(f *os.File, r *Report) error { return WriteJUnit(f, r) }
+	case ".md", ".markdown":
+		write = func(f *os.File, r *Report) error { return WriteMarkdown(f, r) }
+	default:
+		return fmt.Errorf("formato de relatório não suportado para %s (use .xml para JUnit ou .md para Markdown)", path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao criar relatório: %w", err)
+	}
+	defer file.Close()
+
+	if err := write(file, r); err != nil {
+		return fmt.Errorf("erro ao escrever relatório %s: %w", path, err)
+	}
+	return nil
+}
+
+// CheckPath valida a extensão do relatório antes de executar o comando
+func CheckPath(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml", ".md", ".markdown":
+		return nil
+	}
+	return fmt.Errorf("formato de relatório não suportado para %s (use .xml para JUnit ou .md para Markdown)", path)
+}
@@ -0,0 +1,519 @@
+// Package secrets criptografa valores sensíveis do manifest (senhas e campos !sensitive)
+//
+// Cada valor vira um blob inline ENC[...]: o valor é cifrado com AES-256-GCM usando
+// uma chave de dados aleatória, que é embrulhada para cada destinatário:
+//   - X25519 (estilo age): ECDH com chave efêmera + HKDF-SHA256 → AES-256-GCM
+//   - AES-256: chave simétrica compartilhada → AES-256-GCM
+//
+// Com vários destinatários, qualquer uma das chaves privadas correspondentes decifra o
+// valor; assim cada CI recebe apenas a chave do seu ambiente. As chaves ficam em um
+// arquivo local (uma por linha, comentários com #), gerado por 'keygen'
+//
+// Senhas de usuários são ligadas ao email do usuário (dados adicionais do GCM): um blob
+// copiado para outro usuário não decifra. Isso não substitui a revisão das mudanças no
+// manifest, pois quem tem uma chave pública consegue criptografar qualquer valor
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prefixos das chaves em texto
+const (
+	X25519SecretPrefix    = "SAGEP-X25519-KEY:"
+	X25519RecipientPrefix = "sagep-x25519:"
+	AESKeyPrefix          = "SAGEP-AES256-KEY:"
+)
+
+// Tipos de destinatário no blob
+const (
+	typeX25519 = "x25519"
+	typeAES    = "aes256"
+)
+
+// Delimitadores do blob inline
+const (
+	blobPrefix = "ENC["
+	blobSuffix = "]"
+)
+
+// ErrNoKey indica que nenhuma chave carregada é destinatária do blob
+var ErrNoKey = errors.New("nenhuma chave disponível decifra o valor")
+
+// blobVersion é a versão do formato do blob: o valor é ligado ao contexto do campo
+// (dados adicionais do GCM), então um blob copiado para outro campo não decifra
+const blobVersion = 2
+
+// stanza é a chave de dados embrulhada para um destinatário
+type stanza struct {
+	Type      string `json:"t"`
+	ID        string `json:"id"`
+	Ephemeral string `json:"e,omitempty"` // Chave pública efêmera (x25519)
+	Key       string `json:"k"`           // nonce || chave de dados cifrada
+}
+
+// envelope é o conteúdo (JSON em base64url) de ENC[...]
+type envelope struct {
+	Version    int      `json:"v"`
+	Recipients []stanza `json:"r"`
+	Nonce      string   `json:"n"`
+	Ciphertext string   `json:"c"`
+}
+
+// Recipient é um destinatário: quem poderá decifrar o valor
+type Recipient interface {
+	ID() string
+	String() string
+	wrap(dataKey []byte) (stanza, error)
+}
+
+// Identity é uma chave privada (ou simétrica) capaz de decifrar valores
+type Identity interface {
+	ID() string
+	Recipient() Recipient
+	unwrap(s stanza) ([]byte, error)
+}
+
+// X25519Recipient é a chave pública de um destinatário X25519
+type X25519Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// ID é o identificador curto da chave (gravado no blob para achar a chave certa)
+func (r *X25519Recipient) ID() string { return keyID(typeX25519, r.key.Bytes()) }
+
+// String retorna a chave pública no formato sagep-x25519:...
+func (r *X25519Recipient) String() string {
+	return X25519RecipientPrefix + base64.RawURLEncoding.EncodeToString(r.key.Bytes())
+}
+
+func (r *X25519Recipient) wrap(dataKey []byte) (stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return stanza{}, err
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return stanza{}, err
+	}
+	kek := x25519KEK(shared, ephemeral.PublicKey().Bytes(), r.key.Bytes())
+	sealed, err := seal(kek, dataKey, nil)
+	if err != nil {
+		return stanza{}, err
+	}
+	return stanza{
+		Type:      typeX25519,
+		ID:        r.ID(),
+		Ephemeral: base64.RawURLEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+		Key:       base64.RawURLEncoding.EncodeToString(sealed),
+	}, nil
+}
+
+// X25519Identity é uma chave privada X25519
+type X25519Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateX25519 gera uma nova chave privada X25519
+func GenerateX25519() (*X25519Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave: %w", err)
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// ID é o identificador da chave pública correspondente
+func (i *X25519Identity) ID() string { return i.Recipient().ID() }
+
+// Recipient retorna a chave pública correspondente
+func (i *X25519Identity) Recipient() Recipient { return &X25519Recipient{key: i.key.PublicKey()} }
+
+// String retorna a chave privada no formato SAGEP-X25519-KEY:...
+func (i *X25519Identity) String() string {
+	return X25519SecretPrefix + base64.RawURLEncoding.EncodeToString(i.key.Bytes())
+}
+
+func (i *X25519Identity) unwrap(s stanza) ([]byte, error) {
+	ephemeralBytes, err := base64.RawURLEncoding.DecodeString(s.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := i.key.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(s.Key)
+	if err != nil {
+		return nil, err
+	}
+	return open(x25519KEK(shared, ephemeralBytes, i.key.PublicKey().Bytes()), sealed, nil)
+}
+
+// AESKey é uma chave simétrica AES-256: é destinatário e identidade ao mesmo tempo
+type AESKey struct {
+	key []byte
+}
+
+// GenerateAES gera uma nova chave AES-256
+func GenerateAES() (*AESKey, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave: %w", err)
+	}
+	return &AESKey{key: key}, nil
+}
+
+// ID é o identificador curto da chave (não revela a chave)
+func (k *AESKey) ID() string { return keyID(typeAES, k.key) }
+
+// Recipient retorna a própria chave (simétrica)
+func (k *AESKey) Recipient() Recipient { return k }
+
+// String retorna a chave no formato SAGEP-AES256-KEY:...
+func (k *AESKey) String() string {
+	return AESKeyPrefix + base64.RawURLEncoding.EncodeToString(k.key)
+}
+
+func (k *AESKey) wrap(dataKey []byte) (stanza, error) {
+	sealed, err := seal(k.key, dataKey, nil)
+	if err != nil {
+		return stanza{}, err
+	}
+	return stanza{Type: typeAES, ID: k.ID(), Key: base64.RawURLEncoding.EncodeToString(sealed)}, nil
+}
+
+func (k *AESKey) unwrap(s stanza) ([]byte, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(s.Key)
+	if err != nil {
+		return nil, err
+	}
+	return open(k.key, sealed, nil)
+}
+
+// ParseRecipient lê uma chave pública (sagep-x25519:...) ou simétrica (SAGEP-AES256-KEY:...)
+func ParseRecipient(s string) (Recipient, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, X25519RecipientPrefix):
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, X25519RecipientPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("destinatário inválido %q: %w", s, err)
+		}
+		key, err := ecdh.X25519().NewPublicKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("destinatário inválido %q: %w", s, err)
+		}
+		return &X25519Recipient{key: key}, nil
+	case strings.HasPrefix(s, AESKeyPrefix):
+		id, err := parseIdentity(s)
+		if err != nil {
+			return nil, err
+		}
+		return id.Recipient(), nil
+	}
+	return nil, fmt.Errorf("destinatário inválido %q (esperado %s... ou %s...)", s, X25519RecipientPrefix, AESKeyPrefix)
+}
+
+// ParseRecipients lê destinatários, um por linha (linhas vazias e comentários # são ignorados)
+func ParseRecipients(data []byte) ([]Recipient, error) {
+	var recipients []Recipient
+	for _, line := range keyLines(data) {
+		r, err := ParseRecipient(line)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// ParseIdentities lê chaves privadas, uma por linha (linhas vazias e comentários # são ignorados)
+func ParseIdentities(data []byte) ([]Identity, error) {
+	var identities []Identity
+	for _, line := range keyLines(data) {
+		id, err := parseIdentity(line)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, id)
+	}
+	return identities, nil
+}
+
+func parseIdentity(s string) (Identity, error) {
+	switch {
+	case strings.HasPrefix(s, X25519SecretPrefix):
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, X25519SecretPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("chave X25519 inválida: %w", err)
+		}
+		key, err := ecdh.X25519().NewPrivateKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("chave X25519 inválida: %w", err)
+		}
+		return &X25519Identity{key: key}, nil
+	case strings.HasPrefix(s, AESKeyPrefix):
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, AESKeyPrefix))
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("chave AES-256 inválida (esperados 32 bytes em base64url)")
+		}
+		return &AESKey{key: raw}, nil
+	}
+	// Não ecoar a linha: pode ser uma chave privada mal formatada
+	return nil, fmt.Errorf("linha de chave não reconhecida (esperado %s... ou %s...)", X25519SecretPrefix, AESKeyPrefix)
+}
+
+// DefaultKeyFile retorna o arquivo de chaves padrão (~/.config/sagep-auth/keys.txt)
+func DefaultKeyFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("erro ao localizar diretório de configuração: %w", err)
+	}
+	return filepath.Join(dir, "sagep-auth", "keys.txt"), nil
+}
+
+// LoadIdentities carrega as chaves de decifragem
+// Fontes: keyFiles (se informados) ou SAGEP_AUTH_KEY_FILE (lista separada por ':'),
+// SAGEP_AUTH_KEY (chaves inline, para secrets de CI) e o arquivo padrão, se existir
+func LoadIdentities(keyFiles []string) ([]Identity, error) {
+	var identities []Identity
+	if inline := os.Getenv("SAGEP_AUTH_KEY"); inline != "" {
+		ids, err := ParseIdentities([]byte(inline))
+		if err != nil {
+			return nil, fmt.Errorf("SAGEP_AUTH_KEY: %w", err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	if len(keyFiles) == 0 {
+		if env := os.Getenv("SAGEP_AUTH_KEY_FILE"); env != "" {
+			keyFiles = filepath.SplitList(env)
+		} else if path, err := DefaultKeyFile(); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				keyFiles = []string{path}
+			}
+		}
+	}
+	for _, path := range keyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler arquivo de chaves: %w", err)
+		}
+		ids, err := ParseIdentities(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		identities = append(identities, ids...)
+	}
+	return identities, nil
+}
+
+// IsEncrypted indica se o valor é um blob ENC[...]
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, blobPrefix) && strings.HasSuffix(value, blobSuffix)
+}
+
+// Encrypt cifra o valor para os destinatários e retorna o blob ENC[...]
+// context liga o valor ao campo (ex: email do usuário da senha); Decrypt precisa do mesmo contexto
+func Encrypt(plaintext, context string, recipients []Recipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("nenhum destinatário para criptografar")
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("erro ao gerar chave de dados: %w", err)
+	}
+
+	env := envelope{Version: blobVersion}
+	for _, r := range recipients {
+		s, err := r.wrap(dataKey)
+		if err != nil {
+			return "", fmt.Errorf("erro ao criptografar para %s: %w", r.ID(), err)
+		}
+		env.Recipients = append(env.Recipients, s)
+	}
+	sealed, err := seal(dataKey, []byte(plaintext), additionalData(context))
+	if err != nil {
+		return "", err
+	}
+	env.Nonce = base64.RawURLEncoding.EncodeToString(sealed[:12])
+	env.Ciphertext = base64.RawURLEncoding.EncodeToString(sealed[12:])
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return blobPrefix + base64.RawURLEncoding.EncodeToString(data) + blobSuffix, nil
+}
+
+// Decrypt decifra um blob ENC[...] com a primeira chave que for destinatária dele
+// context deve ser o mesmo usado em Encrypt
+func Decrypt(blob, context string, identities []Identity) (string, error) {
+	env, err := parseBlob(blob)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0, len(env.Recipients))
+	for _, s := range env.Recipients {
+		ids = append(ids, s.ID)
+		for _, id := range identities {
+			if id.ID() != s.ID {
+				continue
+			}
+			dataKey, err := id.unwrap(s)
+			if err != nil {
+				return "", fmt.Errorf("chave %s não decifrou o valor (blob alterado?)", s.ID)
+			}
+			sealed, err := decodeSealed(env)
+			if err != nil {
+				return "", err
+			}
+			plaintext, err := open(dataKey, sealed, additionalData(context))
+			if err != nil {
+				return "", fmt.Errorf("valor criptografado alterado, corrompido ou copiado de outro campo")
+			}
+			return string(plaintext), nil
+		}
+	}
+	return "", fmt.Errorf("%w (destinatários: %s)", ErrNoKey, strings.Join(ids, ", "))
+}
+
+// BlobRecipients retorna os IDs dos destinatários de um blob ENC[...]
+func BlobRecipients(blob string) ([]string, error) {
+	env, err := parseBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(env.Recipients))
+	for _, s := range env.Recipients {
+		ids = append(ids, s.ID)
+	}
+	return ids, nil
+}
+
+func parseBlob(blob string) (*envelope, error) {
+	if !IsEncrypted(blob) {
+		return nil, fmt.Errorf("valor não está no formato ENC[...]")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(blob, blobPrefix), blobSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("blob ENC[...] inválido: %w", err)
+	}
+	var env envelope
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&env); err != nil {
+		return nil, fmt.Errorf("blob ENC[...] inválido: %w", err)
+	}
+	if env.Version != blobVersion {
+		return nil, fmt.Errorf("versão %d de ENC[...] não suportada (criptografe o valor de novo com 'encrypt')", env.Version)
+	}
+	return &env, nil
+}
+
+func decodeSealed(env *envelope) ([]byte, error) {
+	nonce, err := base64.RawURLEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("blob ENC[...] inválido: %w", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("blob ENC[...] inválido: %w", err)
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+// additionalData são os dados adicionais do GCM: ligam o valor ao contexto do campo
+func additionalData(context string) []byte {
+	return []byte("sagep-auth/enc/v2\n" + context)
+}
+
+// seal cifra com AES-256-GCM e retorna nonce || ciphertext
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decifra nonce || ciphertext com AES-256-GCM
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("dados cifrados truncados")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// x25519KEK deriva a chave que embrulha a chave de dados (HKDF-SHA256)
+// O salt liga a derivação à chave efêmera e ao destinatário
+func x25519KEK(shared, ephemeral, recipient []byte) []byte {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	return hkdfSHA256(shared, salt, []byte("sagep-auth/x25519/v1"))
+}
+
+// hkdfSHA256 implementa HKDF (RFC 5869) para uma saída de 32 bytes
+func hkdfSHA256(secret, salt, info []byte) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+// keyID é o identificador curto de uma chave (8 dígitos hex do SHA-256)
+func keyID(kind string, key []byte) string {
+	sum := sha256.Sum256(append([]byte("sagep-auth/"+kind+"/"), key...))
+	return hex.EncodeToString(sum[:4])
+}
+
+// keyLines retorna as linhas não vazias e sem comentário de um arquivo de chaves
+func keyLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
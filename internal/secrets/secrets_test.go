@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testUsers = `users:
+  - email: master@sagep.com.br
+    password: Senha#DoMaster2026
+  - email: operador@sagep.com.br
+    password: Senha#DoOperador2026
+    name: !sensitive Operador
+`
+
+func parseTree(t *testing.T, data string) *yaml.Node {
+	t.Helper()
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		t.Fatal(err)
+	}
+	return &root
+}
+
+func userField(root *yaml.Node, user int, key string) *yaml.Node {
+	item := root.Content[0].Content[1].Content[user]
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value == key {
+			return item.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func TestEncryptTreeRoundTrip(t *testing.T) {
+	key, err := GenerateX25519()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := parseTree(t, testUsers)
+
+	count, err := EncryptTree(root, []Recipient{key.Recipient()})
+	if err != nil || count != 3 {
+		t.Fatalf("esperava 3 valores criptografados, obteve %d (%v)", count, err)
+	}
+	if !HasEncrypted(root) || strings.Contains(userField(root, 0, "password").Value, "Master") {
+		t.Fatal("senha deveria estar criptografada")
+	}
+
+	if _, err := DecryptTree(root, []Identity{key}); err != nil {
+		t.Fatal(err)
+	}
+	if got := userField(root, 1, "password").Value; got != "Senha#DoOperador2026" {
+		t.Fatalf("senha decifrada diferente: %q", got)
+	}
+	if got := userField(root, 1, "name").Value; got != "Operador" {
+		t.Fatalf("campo !sensitive decifrado diferente: %q", got)
+	}
+}
+
+func TestPasswordBoundToUser(t *testing.T) {
+	key, err := GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := parseTree(t, testUsers)
+	if _, err := EncryptTree(root, []Recipient{key}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copiar a senha do operador para o master não pode trocar a senha do master
+	userField(root, 0, "password").Value = userField(root, 1, "password").Value
+	_, err = DecryptTree(root, []Identity{key})
+	if err == nil || !strings.Contains(err.Error(), "users[0].password") {
+		t.Fatalf("esperava erro ao decifrar senha copiada de outro usuário, obteve %v", err)
+	}
+}
+
+func TestDecryptRejectsUnboundBlob(t *testing.T) {
+	key, err := GenerateAES()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blob v1 (sem dados adicionais): decifraria em qualquer campo, então é recusado
+	blob, err := Encrypt("Senha#Antiga2025", "", []Recipient{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := parseBlob(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey, err := key.unwrap(env.Recipients[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := seal(dataKey, []byte("Senha#Antiga2025"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := *env
+	v1.Version = 1
+	v1.Nonce = base64.RawURLEncoding.EncodeToString(sealed[:12])
+	v1.Ciphertext = base64.RawURLEncoding.EncodeToString(sealed[12:])
+	data, err := json.Marshal(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := blobPrefix + base64.RawURLEncoding.EncodeToString(data) + blobSuffix
+
+	if _, err := Decrypt(legacy, "email:qualquer@sagep.com.br", []Identity{key}); err == nil {
+		t.Fatal("blob v1 não deveria decifrar")
+	}
+	if _, err := Decrypt(blob, "email:outro@sagep.com.br", []Identity{key}); err == nil {
+		t.Fatal("blob v2 não deveria decifrar com outro contexto")
+	}
+}
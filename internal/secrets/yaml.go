@@ -0,0 +1,190 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SensitiveTag marca um campo do manifest para ser criptografado (ex: name: !sensitive "João")
+// Campos password são sempre sensíveis; a tag é mantida no valor criptografado
+const SensitiveTag = "!sensitive"
+
+// EncryptTree criptografa os campos password e !sensitive ainda em texto claro
+// Retorna quantos valores foram criptografados
+func EncryptTree(root *yaml.Node, recipients []Recipient) (int, error) {
+	count := 0
+	err := walk(root, "", func(node *yaml.Node, path string, isPassword bool, context string) error {
+		if IsEncrypted(node.Value) || (!isPassword && node.Tag != SensitiveTag) || node.Value == "" {
+			return nil
+		}
+		blob, err := Encrypt(node.Value, context, recipients)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		node.Value = blob
+		node.Style = 0
+		if node.Tag != SensitiveTag {
+			node.Tag = "!!str"
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// DecryptTree substitui os blobs ENC[...] pelo texto claro
+// Retorna quantos valores foram decifrados
+func DecryptTree(root *yaml.Node, identities []Identity) (int, error) {
+	count := 0
+	err := walk(root, "", func(node *yaml.Node, path string, _ bool, context string) error {
+		if !IsEncrypted(node.Value) {
+			return nil
+		}
+		plaintext, err := Decrypt(node.Value, context, identities)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		node.Value = plaintext
+		node.Style = yaml.DoubleQuotedStyle
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// DecryptAvailable decifra os blobs ENC[...] que as chaves carregadas abrem e mantém
+// os demais cifrados (comandos que não usam senhas, como matrix e graph)
+// Retorna quantos valores ficaram cifrados; blobs alterados continuam sendo erro
+func DecryptAvailable(root *yaml.Node, identities []Identity) (int, error) {
+	pending := 0
+	err := walk(root, "", func(node *yaml.Node, path string, _ bool, context string) error {
+		if !IsEncrypted(node.Value) {
+			return nil
+		}
+		plaintext, err := Decrypt(node.Value, context, identities)
+		if errors.Is(err, ErrNoKey) {
+			pending++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		node.Value = plaintext
+		node.Style = yaml.DoubleQuotedStyle
+		return nil
+	})
+	return pending, err
+}
+
+// HasEncrypted indica se a árvore contém algum blob ENC[...]
+func HasEncrypted(root *yaml.Node) bool {
+	found := false
+	walk(root, "", func(node *yaml.Node, _ string, _ bool, _ string) error {
+		if IsEncrypted(node.Value) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// EncryptedField é um valor ENC[...] da árvore
+type EncryptedField struct {
+	Path       string   // Ex: users[0].password
+	Password   bool     // Valor de uma chave password (os demais são campos !sensitive)
+	Recipients []string // IDs das chaves destinatárias
+}
+
+// EncryptedFields lista os valores ENC[...] da árvore e seus destinatários
+func EncryptedFields(root *yaml.Node) ([]EncryptedField, error) {
+	var fields []EncryptedField
+	err := walk(root, "", func(node *yaml.Node, path string, isPassword bool, _ string) error {
+		if !IsEncrypted(node.Value) {
+			return nil
+		}
+		ids, err := BlobRecipients(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fields = append(fields, EncryptedField{Path: path, Password: isPassword, Recipients: ids})
+		return nil
+	})
+	return fields, err
+}
+
+// visitFunc recebe cada escalar com o caminho (ex: users[0].password)
+// isPassword indica que o escalar é o valor de uma chave password; context é o contexto
+// ao qual o valor criptografado fica ligado (ver passwordContext)
+type visitFunc func(node *yaml.Node, path string, isPassword bool, context string) error
+
+// walk visita os escalares da árvore
+func walk(node *yaml.Node, path string, visit visitFunc) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := walk(child, path, visit); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if value.Kind == yaml.ScalarNode {
+				context := ""
+				if key == "password" {
+					context = passwordContext(node)
+				}
+				if err := visit(value, childPath, key == "password", context); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walk(value, childPath, visit); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			if child.Kind == yaml.ScalarNode {
+				if err := visit(child, childPath, false, ""); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := walk(child, childPath, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// passwordContext liga a senha ao email do usuário (mapa que contém o password)
+// Outros campos e emails !sensitive ficam sem contexto: o valor do email mudaria ao criptografá-lo
+func passwordContext(mapping *yaml.Node) string {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "email" {
+			continue
+		}
+		email := mapping.Content[i+1]
+		if email.Kind != yaml.ScalarNode || email.Tag == SensitiveTag || IsEncrypted(email.Value) {
+			return ""
+		}
+		return PasswordContext(email.Value)
+	}
+	return ""
+}
+
+// PasswordContext é o contexto de Encrypt/Decrypt da senha do usuário com este email
+func PasswordContext(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}